@@ -31,5 +31,5 @@ func InitDB() {
 	}
 
 	// Migrate the schema
-	DB.AutoMigrate(&userModel.User{}, &projectModel.Project{})
+	DB.AutoMigrate(&userModel.User{}, &projectModel.Project{}, &projectModel.Transaction{}, &projectModel.HealthPolicy{}, &projectModel.Scenario{}, &projectModel.BudgetLine{})
 }
@@ -0,0 +1,284 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AnalysisAnomalies is the analysis type that flags unusual transactions and
+// months.
+const AnalysisAnomalies = "anomalies"
+
+// How an anomaly score was computed.
+const (
+	AnomalyMethodMAD           = "mad"
+	AnomalyMethodRollingZScore = "rolling_z_score"
+)
+
+const (
+	DefaultAnomalyWindow = 6
+	MinAnomalyWindow     = 3
+	MaxAnomalyWindow     = 24
+
+	// Modified z-scores above 3.5 are the usual outlier cut for the median
+	// absolute deviation; plain z-scores use 3.
+	DefaultMADThreshold    = 3.5
+	DefaultZScoreThreshold = 3.0
+
+	// A category needs this many flows in the same direction to be its own
+	// baseline; smaller ones are compared with all flows in that direction.
+	minCategoryBaseline = 10
+	// madScale turns a median absolute deviation into a standard deviation
+	// for normally distributed data, meanADScale does the same for the mean
+	// absolute deviation used when the median one is zero.
+	madScale    = 0.6745
+	meanADScale = 1.253314
+)
+
+// AnomalyOptions configure DetectAnomalies. WindowMonths defaults to
+// DefaultAnomalyWindow. A zero Threshold uses the default of each method,
+// any other value applies to both.
+type AnomalyOptions struct {
+	WindowMonths int
+	Threshold    float64
+}
+
+// AnomalyBaseline is what a point was compared against: the median and
+// median absolute deviation of a group of transactions, or the mean and
+// standard deviation of the months From to To.
+type AnomalyBaseline struct {
+	Method    string  `json:"method"`
+	Direction string  `json:"direction,omitempty"`
+	Category  string  `json:"category,omitempty"`
+	Count     int     `json:"count"`
+	Center    float64 `json:"center"`
+	Spread    float64 `json:"spread"`
+	From      string  `json:"from,omitempty"`
+	To        string  `json:"to,omitempty"`
+}
+
+// TransactionAnomaly is a transaction whose size stands out from the other
+// flows in its direction.
+type TransactionAnomaly struct {
+	Date        time.Time       `json:"date"`
+	Value       float64         `json:"value"`
+	Category    string          `json:"category,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Score       float64         `json:"score"`
+	Baseline    AnomalyBaseline `json:"baseline"`
+}
+
+// MonthAnomaly is a month whose inflow, outflow or net flow (Metric) stands
+// out from the months before it. A positive Score is above the baseline.
+type MonthAnomaly struct {
+	Period   string          `json:"period"`
+	Start    time.Time       `json:"start"`
+	Metric   string          `json:"metric"`
+	Value    float64         `json:"value"`
+	Score    float64         `json:"score"`
+	Baseline AnomalyBaseline `json:"baseline"`
+}
+
+type AnomalyReport struct {
+	WindowMonths    int                  `json:"window_months"`
+	MADThreshold    float64              `json:"mad_threshold"`
+	ZScoreThreshold float64              `json:"z_score_threshold"`
+	Transactions    []TransactionAnomaly `json:"transactions"`
+	Months          []MonthAnomaly       `json:"months"`
+}
+
+// DetectAnomalies scores a series sorted by date two ways. Each transaction
+// gets the modified z-score of its absolute amount against the other flows
+// in the same direction, of its category when there are enough of them;
+// only unusually large amounts are flagged. Each calendar month gets the
+// z-score of its inflow, outflow and net flow against the WindowMonths
+// months before it, months without transactions counting as zero.
+func DetectAnomalies(series []TimeSeriesDataPoint, options AnomalyOptions) *AnomalyReport {
+	if options.WindowMonths <= 0 {
+		options.WindowMonths = DefaultAnomalyWindow
+	}
+	report := &AnomalyReport{
+		WindowMonths:    options.WindowMonths,
+		MADThreshold:    DefaultMADThreshold,
+		ZScoreThreshold: DefaultZScoreThreshold,
+		Transactions:    []TransactionAnomaly{},
+		Months:          []MonthAnomaly{},
+	}
+	if options.Threshold > 0 {
+		report.MADThreshold = options.Threshold
+		report.ZScoreThreshold = options.Threshold
+	}
+
+	report.Transactions = transactionAnomalies(series, report.MADThreshold)
+	report.Months = monthAnomalies(series, options.WindowMonths, report.ZScoreThreshold)
+	return report
+}
+
+func transactionAnomalies(series []TimeSeriesDataPoint, threshold float64) []TransactionAnomaly {
+	// Categories are grouped ignoring case; byCategory is false for the
+	// group of all flows in a direction.
+	type group struct {
+		direction  string
+		category   string
+		byCategory bool
+	}
+	members := map[group][]int{}
+	for i, p := range series {
+		if p.Value == 0 {
+			continue
+		}
+		direction := flowDirection(p.Value)
+		all := group{direction: direction}
+		members[all] = append(members[all], i)
+		if p.Category != "" {
+			key := group{direction, strings.ToLower(p.Category), true}
+			members[key] = append(members[key], i)
+		}
+	}
+
+	baselines := map[group]*AnomalyBaseline{}
+	baselineFor := func(g group) *AnomalyBaseline {
+		if baseline, ok := baselines[g]; ok {
+			return baseline
+		}
+		values := make([]float64, len(members[g]))
+		for i, index := range members[g] {
+			values[i] = math.Abs(series[index].Value)
+		}
+		baseline := robustBaseline(values)
+		if baseline != nil {
+			baseline.Direction = g.direction
+			if g.byCategory {
+				baseline.Category = series[members[g][0]].Category
+			}
+		}
+		baselines[g] = baseline
+		return baseline
+	}
+
+	anomalies := []TransactionAnomaly{}
+	for _, p := range series {
+		if p.Value == 0 {
+			continue
+		}
+		direction := flowDirection(p.Value)
+		g := group{direction, strings.ToLower(p.Category), true}
+		if p.Category == "" || len(members[g]) < minCategoryBaseline {
+			g = group{direction: direction}
+		}
+		baseline := baselineFor(g)
+		if baseline == nil {
+			continue
+		}
+
+		score := (math.Abs(p.Value) - baseline.Center) / baseline.Spread
+		if score < threshold {
+			continue
+		}
+		anomalies = append(anomalies, TransactionAnomaly{
+			Date:        p.Date,
+			Value:       p.Value,
+			Category:    p.Category,
+			Description: p.Description,
+			Score:       score,
+			Baseline:    *baseline,
+		})
+	}
+	return anomalies
+}
+
+// robustBaseline returns the median of values and the spread that turns a
+// distance from it into a modified z-score, or nil when the values do not
+// vary.
+func robustBaseline(values []float64) *AnomalyBaseline {
+	if len(values) < 3 {
+		return nil
+	}
+	center := median(values)
+
+	deviations := make([]float64, len(values))
+	var totalDeviation float64
+	for i, v := range values {
+		deviations[i] = math.Abs(v - center)
+		totalDeviation += deviations[i]
+	}
+
+	spread := median(deviations) / madScale
+	if spread == 0 {
+		// More than half the values are equal; fall back to the mean
+		// absolute deviation so the rest can still be scored.
+		spread = meanADScale * totalDeviation / float64(len(values))
+	}
+	if spread == 0 {
+		return nil
+	}
+	return &AnomalyBaseline{Method: AnomalyMethodMAD, Count: len(values), Center: center, Spread: spread}
+}
+
+func monthAnomalies(series []TimeSeriesDataPoint, window int, threshold float64) []MonthAnomaly {
+	// A last month the data stops part way through is not scored.
+	months := monthlyTotals(series)[:completeMonths(series)]
+	if len(months) <= window {
+		return []MonthAnomaly{}
+	}
+	metrics := map[string][]float64{
+		"inflow":  make([]float64, len(months)),
+		"outflow": make([]float64, len(months)),
+		"net":     make([]float64, len(months)),
+	}
+	for i, month := range months {
+		metrics["inflow"][i] = month.Inflow
+		metrics["outflow"][i] = month.Outflow
+		metrics["net"][i] = month.Net
+	}
+
+	anomalies := []MonthAnomaly{}
+	for i := window; i < len(months); i++ {
+		for _, metric := range []string{"inflow", "outflow", "net"} {
+			values := metrics[metric]
+			mean, stdDev := meanAndStdDev(values[i-window : i])
+			if stdDev == 0 {
+				continue
+			}
+			score := (values[i] - mean) / stdDev
+			if math.Abs(score) < threshold {
+				continue
+			}
+			anomalies = append(anomalies, MonthAnomaly{
+				Period: months[i].Period,
+				Start:  months[i].Start,
+				Metric: metric,
+				Value:  values[i],
+				Score:  score,
+				Baseline: AnomalyBaseline{
+					Method: AnomalyMethodRollingZScore,
+					Count:  window,
+					Center: mean,
+					Spread: stdDev,
+					From:   months[i-window].Period,
+					To:     months[i-1].Period,
+				},
+			})
+		}
+	}
+	return anomalies
+}
+
+func flowDirection(value float64) string {
+	if value < 0 {
+		return "outflow"
+	}
+	return "inflow"
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,142 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDetectAnomaliesTransactionsByCategory(t *testing.T) {
+	var series []TimeSeriesDataPoint
+	for i := 0; i < 20; i++ {
+		series = append(series, TimeSeriesDataPoint{
+			Date:     date(2024, time.January, 1).AddDate(0, 0, 3*i),
+			Value:    -(90 + float64(i%5)*5),
+			Category: "Office",
+		})
+	}
+	series = append(series,
+		TimeSeriesDataPoint{Date: date(2024, time.March, 10), Value: -1000, Category: "office", Description: "Chairs"},
+		// Too few to be their own baseline, so compared with every outflow.
+		TimeSeriesDataPoint{Date: date(2024, time.March, 11), Value: -95, Category: "Travel"},
+		TimeSeriesDataPoint{Date: date(2024, time.March, 12), Value: -100, Category: "Travel"},
+	)
+
+	report := DetectAnomalies(series, AnomalyOptions{})
+
+	if len(report.Transactions) != 1 {
+		t.Fatalf("got %d transaction anomalies, want 1: %+v", len(report.Transactions), report.Transactions)
+	}
+	anomaly := report.Transactions[0]
+	if anomaly.Value != -1000 || anomaly.Description != "Chairs" {
+		t.Errorf("flagged %v %q, want the -1000 chairs", anomaly.Value, anomaly.Description)
+	}
+	baseline := anomaly.Baseline
+	if baseline.Method != AnomalyMethodMAD || baseline.Direction != "outflow" || baseline.Category != "Office" || baseline.Count != 21 {
+		t.Errorf("baseline = %+v, want the 21 office outflows", baseline)
+	}
+	// Office amounts are 90 to 110 with 1000 on top: median 100, MAD 5.
+	if baseline.Center != 100 || math.Abs(baseline.Spread-5/madScale) > 1e-9 {
+		t.Errorf("baseline center %v spread %v, want 100 and %v", baseline.Center, baseline.Spread, 5/madScale)
+	}
+	if want := (1000 - 100) / (5 / madScale); math.Abs(anomaly.Score-want) > 1e-9 {
+		t.Errorf("score = %v, want %v", anomaly.Score, want)
+	}
+}
+
+func TestDetectAnomaliesMeanDeviationFallback(t *testing.T) {
+	var series []TimeSeriesDataPoint
+	for i := 0; i < 12; i++ {
+		series = append(series, TimeSeriesDataPoint{Date: date(2024, time.January, 1+i), Value: -50})
+	}
+	series = append(series,
+		TimeSeriesDataPoint{Date: date(2024, time.January, 20), Value: -55},
+		TimeSeriesDataPoint{Date: date(2024, time.January, 21), Value: -500},
+	)
+
+	report := DetectAnomalies(series, AnomalyOptions{})
+
+	// More than half the amounts are 50, so the median deviation is zero
+	// and the mean one, (5 + 450) / 14, sets the spread.
+	spread := meanADScale * 455 / 14
+	if len(report.Transactions) != 1 || report.Transactions[0].Value != -500 {
+		t.Fatalf("transaction anomalies = %+v, want only -500", report.Transactions)
+	}
+	if got := report.Transactions[0].Baseline.Spread; math.Abs(got-spread) > 1e-9 {
+		t.Errorf("spread = %v, want %v", got, spread)
+	}
+}
+
+func TestDetectAnomaliesConstantAmounts(t *testing.T) {
+	var series []TimeSeriesDataPoint
+	for i := 0; i < 10; i++ {
+		series = append(series, TimeSeriesDataPoint{Date: date(2024, time.January, 1+i), Value: -50})
+	}
+	if report := DetectAnomalies(series, AnomalyOptions{}); len(report.Transactions) != 0 {
+		t.Errorf("transaction anomalies = %+v, want none when nothing varies", report.Transactions)
+	}
+}
+
+func TestDetectAnomaliesRollingMonths(t *testing.T) {
+	inflows := []float64{1000, 1100, 900, 1000, 1050, 950, 5000, 1000}
+	var series []TimeSeriesDataPoint
+	for i, inflow := range inflows {
+		series = append(series,
+			TimeSeriesDataPoint{Date: date(2024, time.Month(1+i), 5), Value: inflow},
+			TimeSeriesDataPoint{Date: date(2024, time.Month(1+i), 10), Value: -200},
+		)
+	}
+
+	report := DetectAnomalies(series, AnomalyOptions{WindowMonths: 6, Threshold: 3})
+
+	// The window before July has a mean of 1000 and a standard deviation of
+	// sqrt(25000 / 5); the steady outflow has none and is never scored.
+	wantScore := 4000 / math.Sqrt(5000)
+	if len(report.Months) != 2 {
+		t.Fatalf("got %d month anomalies, want inflow and net of 2024-07: %+v", len(report.Months), report.Months)
+	}
+	for i, metric := range []string{"inflow", "net"} {
+		got := report.Months[i]
+		if got.Period != "2024-07" || got.Metric != metric || math.Abs(got.Score-wantScore) > 1e-9 {
+			t.Errorf("month anomaly %d = %s %s %v, want 2024-07 %s %v", i, got.Period, got.Metric, got.Score, metric, wantScore)
+		}
+		if got.Baseline.From != "2024-01" || got.Baseline.To != "2024-06" || got.Baseline.Count != 6 {
+			t.Errorf("baseline = %+v, want 2024-01 to 2024-06", got.Baseline)
+		}
+	}
+	if report.MADThreshold != 3 || report.ZScoreThreshold != 3 {
+		t.Errorf("thresholds = %v / %v, want 3 for both", report.MADThreshold, report.ZScoreThreshold)
+	}
+}
+
+func TestDetectAnomaliesPartialLastMonth(t *testing.T) {
+	inflows := []float64{1000, 1100, 900, 1000, 1050, 950, 1000}
+	var series []TimeSeriesDataPoint
+	for i, inflow := range inflows {
+		series = append(series,
+			TimeSeriesDataPoint{Date: date(2024, time.Month(1+i), 5), Value: inflow},
+			TimeSeriesDataPoint{Date: date(2024, time.Month(1+i), 25), Value: -200},
+		)
+	}
+	// August has only started.
+	series = append(series, TimeSeriesDataPoint{Date: date(2024, time.August, 2), Value: 50})
+
+	if report := DetectAnomalies(series, AnomalyOptions{WindowMonths: 6}); len(report.Months) != 0 {
+		t.Errorf("month anomalies = %+v, want none for the unfinished month", report.Months)
+	}
+}
+
+func TestDetectAnomaliesShortHistory(t *testing.T) {
+	series := []TimeSeriesDataPoint{
+		{Date: date(2024, time.January, 5), Value: 100},
+		{Date: date(2024, time.March, 5), Value: 100000},
+	}
+	report := DetectAnomalies(series, AnomalyOptions{})
+
+	if report.WindowMonths != DefaultAnomalyWindow || report.MADThreshold != DefaultMADThreshold || report.ZScoreThreshold != DefaultZScoreThreshold {
+		t.Errorf("report = %+v, want the defaults", report)
+	}
+	if len(report.Months) != 0 || len(report.Transactions) != 0 {
+		t.Errorf("report = %+v, want no anomalies without enough history", report)
+	}
+}
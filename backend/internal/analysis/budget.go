@@ -0,0 +1,114 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AnalysisBudgetVariance is the analysis type that compares the flows with
+// the project budget.
+const AnalysisBudgetVariance = "budget_variance"
+
+// BudgetEntry is the amount planned for a category in the month starting at
+// Month. Planned amounts are signed like the transactions: spending is
+// negative.
+type BudgetEntry struct {
+	Month    time.Time
+	Category string
+	Planned  float64
+}
+
+// BudgetVariance compares what was planned with what happened. Variance is
+// actual minus planned, so a negative variance always means less cash than
+// planned. VariancePercent is relative to the planned amount and nil when
+// nothing was planned. Month and Category are empty on totals; lines without
+// a category get a label from the catalog and are flagged Uncategorized.
+type BudgetVariance struct {
+	Month           string   `json:"month,omitempty"`
+	Category        string   `json:"category,omitempty"`
+	Uncategorized   bool     `json:"uncategorized,omitempty"`
+	Planned         float64  `json:"planned"`
+	Actual          float64  `json:"actual"`
+	Variance        float64  `json:"variance"`
+	VariancePercent *float64 `json:"variance_percent"`
+}
+
+// BudgetVarianceReport breaks the variance down per month and category, with
+// per month totals and the grand total.
+type BudgetVarianceReport struct {
+	Lines  []BudgetVariance `json:"lines"`
+	Months []BudgetVariance `json:"months"`
+	Total  BudgetVariance   `json:"total"`
+}
+
+// CalculateBudgetVariance matches the series against the budget by calendar
+// month and category, ignoring case; a line is named as the budget spells
+// the category, or as its first flow does when it was not budgeted.
+// Categories that were spent without a budget and budget lines without any
+// flow are both listed. Lines are sorted by month, then category. Language
+// selects the catalog the uncategorized label comes from.
+func CalculateBudgetVariance(series []TimeSeriesDataPoint, budget []BudgetEntry, language string) *BudgetVarianceReport {
+	type key struct{ month, category string }
+	lines := map[key]*BudgetVariance{}
+	line := func(month time.Time, category string) *BudgetVariance {
+		uncategorized := category == ""
+		if uncategorized {
+			category = uncategorizedLabel(language)
+		}
+		k := key{periodLabel(periodStart(month, GranularityMonth), GranularityMonth), strings.ToLower(category)}
+		if lines[k] == nil {
+			lines[k] = &BudgetVariance{Month: k.month, Category: category, Uncategorized: uncategorized}
+		}
+		return lines[k]
+	}
+
+	for _, entry := range budget {
+		line(entry.Month, entry.Category).Planned += entry.Planned
+	}
+	for _, p := range series {
+		line(p.Date, p.Category).Actual += p.Value
+	}
+
+	report := &BudgetVarianceReport{Lines: []BudgetVariance{}, Months: []BudgetVariance{}}
+	for _, l := range lines {
+		report.Lines = append(report.Lines, *l)
+	}
+	sort.Slice(report.Lines, func(i, j int) bool {
+		if report.Lines[i].Month != report.Lines[j].Month {
+			return report.Lines[i].Month < report.Lines[j].Month
+		}
+		return report.Lines[i].Category < report.Lines[j].Category
+	})
+
+	for i := range report.Lines {
+		l := &report.Lines[i]
+		l.setVariance()
+
+		last := len(report.Months) - 1
+		if last == -1 || report.Months[last].Month != l.Month {
+			report.Months = append(report.Months, BudgetVariance{Month: l.Month})
+			last++
+		}
+		report.Months[last].Planned += l.Planned
+		report.Months[last].Actual += l.Actual
+		report.Total.Planned += l.Planned
+		report.Total.Actual += l.Actual
+	}
+	for i := range report.Months {
+		report.Months[i].setVariance()
+	}
+	report.Total.setVariance()
+
+	return report
+}
+
+func (v *BudgetVariance) setVariance() {
+	v.Variance = v.Actual - v.Planned
+	v.VariancePercent = nil
+	if v.Planned != 0 {
+		percent := v.Variance / math.Abs(v.Planned) * 100
+		v.VariancePercent = &percent
+	}
+}
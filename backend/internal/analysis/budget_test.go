@@ -0,0 +1,57 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCalculateBudgetVariance(t *testing.T) {
+	series := []TimeSeriesDataPoint{
+		{Date: date(2025, time.March, 5), Value: -1200, Category: "rent"},
+		{Date: date(2025, time.March, 8), Value: -300, Category: "Travel"},
+		{Date: date(2025, time.March, 9), Value: -50},
+	}
+	budget := []BudgetEntry{
+		{Month: date(2025, time.March, 1), Category: "Rent", Planned: -1000},
+		{Month: date(2025, time.March, 1), Category: "Payroll", Planned: -5000},
+	}
+
+	report := CalculateBudgetVariance(series, budget, "en")
+
+	type line struct {
+		category        string
+		planned, actual float64
+		percent         *float64
+	}
+	percent := func(v float64) *float64 { return &v }
+	want := []line{
+		{"Payroll", -5000, 0, percent(100)},
+		{"Rent", -1000, -1200, percent(-20)},
+		{"Travel", 0, -300, nil},
+		{"Uncategorized", 0, -50, nil},
+	}
+	if len(report.Lines) != len(want) {
+		t.Fatalf("got %d lines, want %d: %+v", len(report.Lines), len(want), report.Lines)
+	}
+	for i, w := range want {
+		got := report.Lines[i]
+		if got.Category != w.category || got.Planned != w.planned || got.Actual != w.actual {
+			t.Errorf("line %d = %s %v/%v, want %s %v/%v", i, got.Category, got.Planned, got.Actual, w.category, w.planned, w.actual)
+		}
+		if (got.VariancePercent == nil) != (w.percent == nil) ||
+			got.VariancePercent != nil && math.Abs(*got.VariancePercent-*w.percent) > 1e-9 {
+			t.Errorf("line %d variance percent = %v, want %v", i, got.VariancePercent, w.percent)
+		}
+	}
+	if !report.Lines[3].Uncategorized {
+		t.Errorf("uncategorized line is not flagged")
+	}
+
+	if got := report.Total; got.Planned != -6000 || got.Actual != -1550 || got.Variance != 4450 {
+		t.Errorf("total = %+v, want planned -6000, actual -1550, variance 4450", got)
+	}
+	if len(report.Months) != 1 || report.Months[0].Month != "2025-03" {
+		t.Errorf("months = %+v, want only 2025-03", report.Months)
+	}
+}
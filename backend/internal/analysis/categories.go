@@ -0,0 +1,90 @@
+package analysis
+
+import (
+	"finview/backend/internal/i18n"
+	"math"
+	"sort"
+)
+
+// uncategorizedCode is the catalog entry naming the data points without a
+// category.
+const uncategorizedCode = "category_uncategorized"
+
+// uncategorizedLabel is the name uncategorized data points are listed under
+// in language.
+func uncategorizedLabel(language string) string {
+	return i18n.T(language, uncategorizedCode, nil)
+}
+
+type CategoryMonth struct {
+	Month   string  `json:"month"`
+	Inflow  float64 `json:"inflow"`
+	Outflow float64 `json:"outflow"`
+	Net     float64 `json:"net"`
+}
+
+// CategorySummary totals a category. Data points without a category are
+// listed under a label from the catalog and flagged Uncategorized.
+type CategorySummary struct {
+	Category      string          `json:"category"`
+	Uncategorized bool            `json:"uncategorized,omitempty"`
+	TotalInflow   float64         `json:"total_inflow"`
+	TotalOutflow  float64         `json:"total_outflow"`
+	Net           float64         `json:"net"`
+	Monthly       []CategoryMonth `json:"monthly"`
+}
+
+// calculateCategoryBreakdown totals inflow and outflow per category, overall
+// and per calendar month. Categories are sorted by name, months
+// chronologically.
+func calculateCategoryBreakdown(series []TimeSeriesDataPoint, language string) []CategorySummary {
+	summaries := map[string]*CategorySummary{}
+	months := map[string]map[string]*CategoryMonth{}
+
+	for _, p := range series {
+		category := p.Category
+		if category == "" {
+			category = uncategorizedLabel(language)
+		}
+
+		summary, ok := summaries[category]
+		if !ok {
+			summary = &CategorySummary{Category: category, Uncategorized: p.Category == ""}
+			summaries[category] = summary
+			months[category] = map[string]*CategoryMonth{}
+		}
+
+		monthKey := p.Date.Format("2006-01")
+		month, ok := months[category][monthKey]
+		if !ok {
+			month = &CategoryMonth{Month: monthKey}
+			months[category][monthKey] = month
+		}
+
+		if p.Value >= 0 {
+			summary.TotalInflow += p.Value
+			month.Inflow += p.Value
+		} else {
+			summary.TotalOutflow += math.Abs(p.Value)
+			month.Outflow += math.Abs(p.Value)
+		}
+		summary.Net += p.Value
+		month.Net += p.Value
+	}
+
+	result := make([]CategorySummary, 0, len(summaries))
+	for category, summary := range summaries {
+		for _, month := range months[category] {
+			summary.Monthly = append(summary.Monthly, *month)
+		}
+		sort.Slice(summary.Monthly, func(i, j int) bool {
+			return summary.Monthly[i].Month < summary.Monthly[j].Month
+		})
+		result = append(result, *summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Category < result[j].Category
+	})
+
+	return result
+}
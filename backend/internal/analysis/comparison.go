@@ -0,0 +1,166 @@
+package analysis
+
+import (
+	"math"
+	"time"
+)
+
+// AnalysisComparison is the analysis type that compares each month with the
+// month before it and with the same month a year earlier.
+const AnalysisComparison = "comparison"
+
+// Change compares a value with an earlier one. Percent is relative to the
+// earlier value and nil when it is zero.
+type Change struct {
+	Previous float64  `json:"previous"`
+	Change   float64  `json:"change"`
+	Percent  *float64 `json:"percent"`
+}
+
+// MetricComparison is a monthly figure with its month-over-month and
+// year-over-year changes, nil when the series does not reach back that far.
+type MetricComparison struct {
+	Value float64 `json:"value"`
+	MoM   *Change `json:"mom"`
+	YoY   *Change `json:"yoy"`
+}
+
+// MonthComparison holds the inflow, outflow and net flow of a calendar
+// month. Outflow is a positive amount, so a positive change means spending
+// went up.
+type MonthComparison struct {
+	Period  string           `json:"period"`
+	Start   time.Time        `json:"start"`
+	Inflow  MetricComparison `json:"inflow"`
+	Outflow MetricComparison `json:"outflow"`
+	Net     MetricComparison `json:"net"`
+}
+
+// TrailingTotals adds up the months From to To.
+type TrailingTotals struct {
+	From    string  `json:"from"`
+	To      string  `json:"to"`
+	Months  int     `json:"months"`
+	Inflow  float64 `json:"inflow"`
+	Outflow float64 `json:"outflow"`
+	Net     float64 `json:"net"`
+}
+
+// RevenueGrowth is the compound growth of the monthly inflow between the
+// first and the last month with any inflow, per month and per year, in
+// percent. Both are nil when there is less than a month between them.
+type RevenueGrowth struct {
+	From          string   `json:"from"`
+	To            string   `json:"to"`
+	Months        int      `json:"months"`
+	StartRevenue  float64  `json:"start_revenue"`
+	EndRevenue    float64  `json:"end_revenue"`
+	MonthlyGrowth *float64 `json:"monthly_growth_percent"`
+	CAGR          *float64 `json:"cagr_percent"`
+}
+
+// ComparisonReport is the result of CalculateComparison. TTM covers the last
+// twelve months of the series, or all of them when there are fewer, and
+// PreviousTTM the twelve before, with the change in net flow, when the series
+// is long enough. Revenue is nil when the series has no inflow.
+type ComparisonReport struct {
+	Months       []MonthComparison `json:"months"`
+	TTM          TrailingTotals    `json:"ttm"`
+	PreviousTTM  *TrailingTotals   `json:"previous_ttm"`
+	TTMNetChange *Change           `json:"ttm_net_change"`
+	Revenue      *RevenueGrowth    `json:"revenue"`
+}
+
+// CalculateComparison compares the calendar months of a series sorted by
+// date. Months without transactions count as zero.
+func CalculateComparison(series []TimeSeriesDataPoint) *ComparisonReport {
+	report := &ComparisonReport{Months: []MonthComparison{}}
+	months := monthlyTotals(series)
+	if len(months) == 0 {
+		return report
+	}
+
+	metric := func(i int, value func(monthTotals) float64) MetricComparison {
+		m := MetricComparison{Value: value(months[i])}
+		if i >= 1 {
+			m.MoM = newChange(value(months[i]), value(months[i-1]))
+		}
+		if i >= 12 {
+			m.YoY = newChange(value(months[i]), value(months[i-12]))
+		}
+		return m
+	}
+	for i, month := range months {
+		report.Months = append(report.Months, MonthComparison{
+			Period:  month.Period,
+			Start:   month.Start,
+			Inflow:  metric(i, func(m monthTotals) float64 { return m.Inflow }),
+			Outflow: metric(i, func(m monthTotals) float64 { return m.Outflow }),
+			Net:     metric(i, func(m monthTotals) float64 { return m.Net }),
+		})
+	}
+
+	last := len(months)
+	report.TTM = trailingTotals(months[max(last-12, 0):last])
+	if last >= 24 {
+		previous := trailingTotals(months[last-24 : last-12])
+		report.PreviousTTM = &previous
+		report.TTMNetChange = newChange(report.TTM.Net, previous.Net)
+	}
+	report.Revenue = revenueGrowth(months)
+	return report
+}
+
+func trailingTotals(months []monthTotals) TrailingTotals {
+	totals := TrailingTotals{
+		From:   months[0].Period,
+		To:     months[len(months)-1].Period,
+		Months: len(months),
+	}
+	for _, m := range months {
+		totals.Inflow += m.Inflow
+		totals.Outflow += m.Outflow
+		totals.Net += m.Net
+	}
+	return totals
+}
+
+func revenueGrowth(months []monthTotals) *RevenueGrowth {
+	first, last := -1, -1
+	for i, m := range months {
+		if m.Inflow > 0 {
+			if first == -1 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first == -1 {
+		return nil
+	}
+
+	growth := &RevenueGrowth{
+		From:         months[first].Period,
+		To:           months[last].Period,
+		Months:       last - first,
+		StartRevenue: months[first].Inflow,
+		EndRevenue:   months[last].Inflow,
+	}
+	if growth.Months > 0 {
+		ratio := growth.EndRevenue / growth.StartRevenue
+		monthly := (math.Pow(ratio, 1/float64(growth.Months)) - 1) * 100
+		annual := (math.Pow(ratio, 12/float64(growth.Months)) - 1) * 100
+		growth.MonthlyGrowth = &monthly
+		growth.CAGR = &annual
+	}
+	return growth
+}
+
+func newChange(value, previous float64) *Change {
+	change := &Change{Previous: previous, Change: value - previous}
+	if previous != 0 {
+		percent := change.Change / math.Abs(previous) * 100
+		change.Percent = &percent
+	}
+	return change
+}
@@ -0,0 +1,154 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// comparisonSeries has 26 months from January 2023: an inflow growing by
+// 1000 a month, starting at 1000, and a fixed outflow of 500. June 2023 has
+// no transactions.
+func comparisonSeries() []TimeSeriesDataPoint {
+	var series []TimeSeriesDataPoint
+	for m := 0; m < 26; m++ {
+		if m == 5 {
+			continue
+		}
+		series = append(series,
+			TimeSeriesDataPoint{Date: date(2023, time.Month(1+m), 10), Value: 1000 * float64(m+1)},
+			TimeSeriesDataPoint{Date: date(2023, time.Month(1+m), 11), Value: -500},
+		)
+	}
+	return series
+}
+
+func assertChange(t *testing.T, name string, got *Change, previous, change float64, percent *float64) {
+	t.Helper()
+	if got == nil {
+		t.Errorf("%s = nil, want a change from %v", name, previous)
+		return
+	}
+	if got.Previous != previous || got.Change != change {
+		t.Errorf("%s = %v from %v, want %v from %v", name, got.Change, got.Previous, change, previous)
+	}
+	switch {
+	case percent == nil && got.Percent != nil:
+		t.Errorf("%s percent = %v, want nil", name, *got.Percent)
+	case percent != nil && (got.Percent == nil || math.Abs(*got.Percent-*percent) > 1e-9):
+		t.Errorf("%s percent = %v, want %v", name, got.Percent, *percent)
+	}
+}
+
+func percentOf(v float64) *float64 { return &v }
+
+func TestCalculateComparisonMonths(t *testing.T) {
+	report := CalculateComparison(comparisonSeries())
+
+	if len(report.Months) != 26 {
+		t.Fatalf("got %d months, want 26 including the empty one", len(report.Months))
+	}
+
+	first := report.Months[0]
+	if first.Period != "2023-01" || first.Inflow.MoM != nil || first.Inflow.YoY != nil {
+		t.Errorf("first month = %+v, want no earlier month to compare with", first)
+	}
+
+	// After the empty month there is nothing to take a percentage of.
+	july := report.Months[6]
+	assertChange(t, "July inflow MoM", july.Inflow.MoM, 0, 7000, nil)
+	assertChange(t, "July outflow MoM", july.Outflow.MoM, 0, 500, nil)
+	if july.Inflow.YoY != nil {
+		t.Errorf("July 2023 YoY = %+v, want nil", july.Inflow.YoY)
+	}
+
+	february := report.Months[13]
+	if february.Period != "2024-02" {
+		t.Fatalf("month 13 = %s, want 2024-02", february.Period)
+	}
+	assertChange(t, "February inflow MoM", february.Inflow.MoM, 13000, 1000, percentOf(1000.0/13000*100))
+	assertChange(t, "February inflow YoY", february.Inflow.YoY, 2000, 12000, percentOf(600))
+	assertChange(t, "February outflow YoY", february.Outflow.YoY, 500, 0, percentOf(0))
+	assertChange(t, "February net YoY", february.Net.YoY, 1500, 12000, percentOf(800))
+}
+
+func TestCalculateComparisonNegativeBase(t *testing.T) {
+	series := []TimeSeriesDataPoint{
+		{Date: date(2024, time.January, 5), Value: -2000},
+		{Date: date(2024, time.February, 5), Value: -1000},
+	}
+	report := CalculateComparison(series)
+
+	// Percentages are relative to the size of the base, so a smaller loss
+	// is an improvement.
+	assertChange(t, "net MoM", report.Months[1].Net.MoM, -2000, 1000, percentOf(50))
+}
+
+func TestCalculateComparisonTrailingTotals(t *testing.T) {
+	report := CalculateComparison(comparisonSeries())
+
+	// March 2024 to February 2025: inflows 15000 to 26000.
+	want := TrailingTotals{From: "2024-03", To: "2025-02", Months: 12, Inflow: 246000, Outflow: 6000, Net: 240000}
+	if report.TTM != want {
+		t.Errorf("TTM = %+v, want %+v", report.TTM, want)
+	}
+	// March 2023 to February 2024 without June: 96000 in and 5500 out.
+	wantPrevious := TrailingTotals{From: "2023-03", To: "2024-02", Months: 12, Inflow: 96000, Outflow: 5500, Net: 90500}
+	if report.PreviousTTM == nil || *report.PreviousTTM != wantPrevious {
+		t.Errorf("previous TTM = %+v, want %+v", report.PreviousTTM, wantPrevious)
+	}
+	assertChange(t, "TTM net change", report.TTMNetChange, 90500, 149500, percentOf(149500.0/90500*100))
+}
+
+func TestCalculateComparisonShortHistory(t *testing.T) {
+	series := []TimeSeriesDataPoint{
+		{Date: date(2024, time.January, 5), Value: 1000},
+		{Date: date(2024, time.March, 5), Value: 1210},
+		{Date: date(2024, time.March, 9), Value: -300},
+	}
+	report := CalculateComparison(series)
+
+	want := TrailingTotals{From: "2024-01", To: "2024-03", Months: 3, Inflow: 2210, Outflow: 300, Net: 1910}
+	if report.TTM != want {
+		t.Errorf("TTM = %+v, want %+v", report.TTM, want)
+	}
+	if report.PreviousTTM != nil || report.TTMNetChange != nil {
+		t.Errorf("previous TTM = %+v, want nil under two years", report.PreviousTTM)
+	}
+
+	revenue := report.Revenue
+	if revenue == nil || revenue.Months != 2 || revenue.StartRevenue != 1000 || revenue.EndRevenue != 1210 {
+		t.Fatalf("revenue = %+v, want 1000 to 1210 over two months", revenue)
+	}
+	// 10% a month compounds to 1.1^12 a year.
+	if revenue.MonthlyGrowth == nil || math.Abs(*revenue.MonthlyGrowth-10) > 1e-9 {
+		t.Errorf("monthly growth = %v, want 10", revenue.MonthlyGrowth)
+	}
+	if want := (math.Pow(1.1, 12) - 1) * 100; revenue.CAGR == nil || math.Abs(*revenue.CAGR-want) > 1e-9 {
+		t.Errorf("CAGR = %v, want %v", revenue.CAGR, want)
+	}
+}
+
+func TestCalculateComparisonRevenueEdgeCases(t *testing.T) {
+	oneMonth := CalculateComparison([]TimeSeriesDataPoint{
+		{Date: date(2024, time.January, 5), Value: 1000},
+		{Date: date(2024, time.January, 20), Value: 500},
+		{Date: date(2024, time.February, 5), Value: -200},
+	})
+	if r := oneMonth.Revenue; r == nil || r.Months != 0 || r.StartRevenue != 1500 || r.MonthlyGrowth != nil || r.CAGR != nil {
+		t.Errorf("revenue = %+v, want a single month of 1500 without growth rates", r)
+	}
+
+	noRevenue := CalculateComparison([]TimeSeriesDataPoint{
+		{Date: date(2024, time.January, 5), Value: -1000},
+		{Date: date(2024, time.February, 5), Value: -1000},
+	})
+	if noRevenue.Revenue != nil {
+		t.Errorf("revenue = %+v, want nil without inflow", noRevenue.Revenue)
+	}
+
+	empty := CalculateComparison(nil)
+	if len(empty.Months) != 0 || empty.Revenue != nil || empty.TTM.Months != 0 {
+		t.Errorf("report = %+v, want an empty report", empty)
+	}
+}
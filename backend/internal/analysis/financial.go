@@ -1,23 +1,40 @@
 package analysis
 
 import (
+	"finview/backend/internal/i18n"
 	"math"
 	"sort"
 	"time"
 )
 
 type TimeSeriesDataPoint struct {
-	Date  time.Time `json:"date"`
-	Value float64   `json:"value"`
+	Date        time.Time         `json:"date"`
+	Value       float64           `json:"value"`
+	Category    string            `json:"category,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
 }
 
+// FinancialHealth summarises the cash position at the last observed date.
+// BurnRate is kept for older clients and equals GrossBurn. BurnWindowMonths
+// is the number of months actually averaged, fewer than requested when the
+// data covers less. RunwayMonths is nil when there is no net burn. FixedBurn
+// is the monthly outflow of the recurring payments still being made and
+// VariableBurn the rest of the gross burn.
 type FinancialHealth struct {
-	CurrentBalance float64 `json:"current_balance"`
-	BurnRate       float64 `json:"burn_rate"`       
-	RunwayMonths   float64 `json:"runway_months"`   
-	Status         string  `json:"status"`         
-	Message        string  `json:"message"`         
-	PredictedDate  string  `json:"predicted_date"`  
+	CurrentBalance   float64  `json:"current_balance"`
+	BurnRate         float64  `json:"burn_rate"`
+	GrossBurn        float64  `json:"gross_burn"`
+	NetBurn          float64  `json:"net_burn"`
+	FixedBurn        float64  `json:"fixed_burn"`
+	VariableBurn     float64  `json:"variable_burn"`
+	BurnWindowMonths int      `json:"burn_window_months"`
+	RunwayMonths     *float64 `json:"runway_months"`
+	StatusCode       string   `json:"status_code"`
+	Status           string   `json:"status"`
+	Message          string   `json:"message"`
+	PredictedDate    string   `json:"predicted_date"`
+	LastObservedDate string   `json:"last_observed_date"`
 }
 
 type CashFlowSummary struct {
@@ -25,6 +42,14 @@ type CashFlowSummary struct {
 	TotalOutflow float64 `json:"total_outflow"` 
 }
 
+// ParseSummary counts the source rows that were used and the ones skipped,
+// keyed by the reason they could not be parsed.
+type ParseSummary struct {
+	ParsedRows      int            `json:"parsed_rows"`
+	SkippedRows     int            `json:"skipped_rows"`
+	SkippedByReason map[string]int `json:"skipped_by_reason"`
+}
+
 type AnalysisResult struct {
 	Type        string                `json:"type"`
 	Column      string                `json:"column"`
@@ -35,16 +60,78 @@ type AnalysisResult struct {
 	TotalReturn float64               `json:"total_return"`
 	Series      []TimeSeriesDataPoint `json:"series"`
 
+	// Balance carried into the first point of the series.
+	OpeningBalance float64 `json:"opening_balance"`
+	// Date range the series was filtered to, when one was requested.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+
 
 	//Graphs
 	BalanceSeries []TimeSeriesDataPoint `json:"balance_series"` 
 	FlowSummary   CashFlowSummary       `json:"flow_summary"`   
 	Health        FinancialHealth       `json:"health"`         
+	Categories    []CategorySummary     `json:"categories"`
+
+	// Set when the series are aggregated by period.
+	Granularity Granularity     `json:"granularity,omitempty"`
+	Periods     []PeriodSummary `json:"periods,omitempty"`
+
+	// Set for the monte_carlo analysis type.
+	Simulation *RunwaySimulation `json:"simulation,omitempty"`
+	// Set for the budget_variance analysis type.
+	BudgetVariance *BudgetVarianceReport `json:"budget_variance,omitempty"`
+	// Set for the anomalies analysis type.
+	Anomalies *AnomalyReport `json:"anomalies,omitempty"`
+	// Set for the recurring analysis type.
+	Recurring *RecurringReport `json:"recurring,omitempty"`
+	// Set for the comparison analysis type.
+	Comparison *ComparisonReport `json:"comparison,omitempty"`
+
+	ParseSummary ParseSummary `json:"parse_summary"`
 }
 
-func CalculateTimeSeriesAnalysis(series []TimeSeriesDataPoint, analysisType, columnName string) *AnalysisResult {
+// DefaultBurnWindowMonths is how many trailing calendar months the burn rate
+// is averaged over; BurnWindowMonths lists the windows clients can pick.
+const DefaultBurnWindowMonths = 3
+
+var BurnWindowMonths = []int{3, 6, 12}
+
+// TimeSeriesOptions tune CalculateTimeSeriesAnalysis. OpeningBalance is the
+// balance accumulated before the first point. BurnWindowMonths defaults to
+// DefaultBurnWindowMonths and HealthPolicy to DefaultHealthPolicy. Language
+// selects the catalog the health and category labels come from. A non-nil Simulation
+// adds a runway simulation to the result.
+type TimeSeriesOptions struct {
+	OpeningBalance   float64
+	BurnWindowMonths int
+	HealthPolicy     *HealthPolicy
+	Language         string
+	Simulation       *SimulationOptions
+}
+
+// CalculateTimeSeriesAnalysis analyses dated points.
+func CalculateTimeSeriesAnalysis(series []TimeSeriesDataPoint, analysisType, columnName string, options TimeSeriesOptions) *AnalysisResult {
+	openingBalance := options.OpeningBalance
+	if options.BurnWindowMonths <= 0 {
+		options.BurnWindowMonths = DefaultBurnWindowMonths
+	}
+	policy := DefaultHealthPolicy()
+	if options.HealthPolicy != nil {
+		policy = *options.HealthPolicy
+	}
+
 	if len(series) == 0 {
-		return &AnalysisResult{Type: analysisType, Column: columnName}
+		result := &AnalysisResult{
+			Type:           analysisType,
+			Column:         columnName,
+			OpeningBalance: openingBalance,
+			Health:         insufficientHealth(openingBalance, options.Language),
+		}
+		if options.Simulation != nil {
+			result.Simulation = SimulateRunway(series, openingBalance, *options.Simulation)
+		}
+		return result
 	}
 
 	sort.Slice(series, func(i, j int) bool {
@@ -59,19 +146,7 @@ func CalculateTimeSeriesAnalysis(series []TimeSeriesDataPoint, analysisType, col
 	}
 
 	count := len(data)
-	var mean float64
-	if count > 0 {
-		mean = sum / float64(count)
-	}
-
-	var stdDev float64
-	if count > 1 {
-		var variance float64
-		for _, val := range data {
-			variance += math.Pow(val-mean, 2)
-		}
-		stdDev = math.Sqrt(variance / float64(count-1))
-	}
+	mean, stdDev := meanAndStdDev(data)
 
 	var totalReturn float64
 	if count > 1 {
@@ -84,7 +159,7 @@ func CalculateTimeSeriesAnalysis(series []TimeSeriesDataPoint, analysisType, col
 
 	var balanceSeries []TimeSeriesDataPoint
 	var totalInflow, totalOutflow float64
-	currentBalance := 0.0
+	currentBalance := openingBalance
 
 	for _, p := range series {
 		if p.Value >= 0 {
@@ -100,7 +175,20 @@ func CalculateTimeSeriesAnalysis(series []TimeSeriesDataPoint, analysisType, col
 		})
 	}
 
-	health := calculateHealth(series, currentBalance, totalOutflow)
+	health := calculateHealth(series, currentBalance, options.BurnWindowMonths, policy, options.Language)
+	streams := DetectRecurring(series)
+	health.FixedBurn = FixedBurn(streams)
+	health.VariableBurn = math.Max(health.GrossBurn-health.FixedBurn, 0)
+
+	var recurring *RecurringReport
+	if analysisType == AnalysisRecurring {
+		recurring = &RecurringReport{Streams: streams, FixedBurn: health.FixedBurn}
+	}
+
+	var simulation *RunwaySimulation
+	if options.Simulation != nil {
+		simulation = SimulateRunway(series, openingBalance, *options.Simulation)
+	}
 
 	return &AnalysisResult{
 		Type:          analysisType,
@@ -111,99 +199,145 @@ func CalculateTimeSeriesAnalysis(series []TimeSeriesDataPoint, analysisType, col
 		StdDev:        stdDev,
 		TotalReturn:   totalReturn,
 		Series:        series,        
+		OpeningBalance: openingBalance,
 		BalanceSeries: balanceSeries, 
 		FlowSummary: CashFlowSummary{
 			TotalInflow:  totalInflow,
 			TotalOutflow: totalOutflow,
 		},
-		Health: health,
+		Health:     health,
+		Categories: calculateCategoryBreakdown(series, options.Language),
+		Simulation: simulation,
+		Recurring:  recurring,
 	}
 }
 
-func calculateHealth(series []TimeSeriesDataPoint, currentBalance, totalOutflow float64) FinancialHealth {
+// calculateHealth averages the burn over the last burnWindow calendar months
+// of data, counting months without transactions, and projects the runway
+// from the last observed date. Gross burn is the monthly outflow, net burn
+// the monthly outflow not covered by inflow. The policy names the status.
+func calculateHealth(series []TimeSeriesDataPoint, currentBalance float64, burnWindow int, policy HealthPolicy, language string) FinancialHealth {
 	if len(series) < 2 {
-		return FinancialHealth{Status: "Dados insuficientes"}
+		return insufficientHealth(currentBalance, language)
 	}
 
 	firstDate := series[0].Date
 	lastDate := series[len(series)-1].Date
-	hoursDiff := lastDate.Sub(firstDate).Hours()
-	monthsDiff := hoursDiff / (24 * 30) 
 
-	if monthsDiff < 1 {
-		monthsDiff = 1 
+	windowStart := monthIndex(lastDate) - burnWindow + 1
+	if first := monthIndex(firstDate); windowStart < first {
+		windowStart = first
 	}
+	months := float64(monthIndex(lastDate) - windowStart + 1)
 
-	burnRate := totalOutflow / monthsDiff
+	var windowInflow, windowOutflow float64
+	for _, p := range series {
+		if monthIndex(p.Date) < windowStart {
+			continue
+		}
+		if p.Value >= 0 {
+			windowInflow += p.Value
+		} else {
+			windowOutflow += math.Abs(p.Value)
+		}
+	}
 
-	
-	netCashFlow := currentBalance / monthsDiff 
+	grossBurn := windowOutflow / months
+	netBurn := (windowOutflow - windowInflow) / months
 
-	runwayMonths := 0.0
-	status := "Indefinido"
-	message := ""
-	predictedDate := ""
+	health := FinancialHealth{
+		CurrentBalance:   currentBalance,
+		BurnRate:         grossBurn,
+		GrossBurn:        grossBurn,
+		NetBurn:          netBurn,
+		BurnWindowMonths: int(months),
+		LastObservedDate: lastDate.Format("2006-01-02"),
+	}
 
-	if netCashFlow >= 0 {
-		status = "Lucrativo"
-		message = "A empresa gera caixa positivo. Sem risco iminente."
-		runwayMonths = 999
+	var outcome HealthOutcome
+	var params i18n.Params
+	if netBurn <= 0 {
+		// No runway: the cash never runs out at this pace.
+		health.NetBurn = 0
+		outcome = policy.Profitable
+	} else if currentBalance > 0 {
+		runwayMonths := currentBalance / netBurn
+		health.RunwayMonths = &runwayMonths
+		health.PredictedDate = addMonths(lastDate, runwayMonths).Format("02/01/2006")
+		outcome, params = policy.classify(runwayMonths)
 	} else {
-		netBurn := math.Abs(netCashFlow)
-
-		if currentBalance > 0 {
-			runwayMonths = currentBalance / netBurn
-			
-			futureDate := time.Now().AddDate(0, 0, int(runwayMonths*30))
-			predictedDate = futureDate.Format("02/01/2006")
-
-			if runwayMonths < 3 {
-				status = "Crítico"
-				message = "Atenção máxima! Caixa para menos de 3 meses."
-			} else if runwayMonths < 6 {
-				status = "Alerta"
-				message = "Cuidado. Caixa para menos de 6 meses."
-			} else {
-				status = "Saudável"
-				message = "Situação estável no médio prazo."
-			}
-		} else {
-			status = "Insolvente"
-			message = "Operação no vermelho. Necessário aporte imediato."
-			runwayMonths = 0
-		}
+		runwayMonths := 0.0
+		health.RunwayMonths = &runwayMonths
+		outcome = policy.Insolvent
 	}
+	outcome = outcome.localize(language, params)
+	health.StatusCode = outcome.Code
+	health.Status = outcome.Status
+	health.Message = outcome.Message
 
+	return health
+}
+
+func insufficientHealth(currentBalance float64, language string) FinancialHealth {
+	outcome := HealthOutcome{Code: HealthInsufficientData}.localize(language, nil)
 	return FinancialHealth{
 		CurrentBalance: currentBalance,
-		BurnRate:       burnRate,
-		RunwayMonths:   runwayMonths,
-		Status:         status,
-		Message:        message,
-		PredictedDate:  predictedDate,
+		StatusCode:     outcome.Code,
+		Status:         outcome.Status,
+		Message:        outcome.Message,
 	}
 }
 
-func CalculateBasicAnalysis(data []float64, analysisType, columnName string) *AnalysisResult {
-	count := len(data)
+// monthIndex numbers calendar months consecutively across years.
+func monthIndex(t time.Time) int {
+	return t.Year()*12 + int(t.Month()) - 1
+}
+
+// addMonths moves t by a fractional number of calendar months; the fraction
+// is taken from the length of the month it falls in.
+func addMonths(t time.Time, months float64) time.Time {
+	whole := math.Floor(months)
+	// Runways of centuries are not meaningful dates.
+	if whole > 12*1000 {
+		whole = 12 * 1000
+	}
+	moved := t.AddDate(0, int(whole), 0)
+	daysInMonth := moved.AddDate(0, 1, 0).Sub(moved).Hours() / 24
+	return moved.Add(time.Duration((months - whole) * daysInMonth * float64(24*time.Hour)))
+}
+
+// meanAndStdDev returns the mean and the sample standard deviation, zero
+// when there are fewer than two values.
+func meanAndStdDev(data []float64) (float64, float64) {
+	if len(data) == 0 {
+		return 0, 0
+	}
+
 	var sum float64
 	for _, val := range data {
 		sum += val
 	}
-
-	var mean float64
-	if count > 0 {
-		mean = sum / float64(count)
-	}
+	mean := sum / float64(len(data))
 
 	var stdDev float64
-	if count > 1 {
+	if len(data) > 1 {
 		var variance float64
 		for _, val := range data {
 			variance += math.Pow(val-mean, 2)
 		}
-		stdDev = math.Sqrt(variance / float64(count-1))
+		stdDev = math.Sqrt(variance / float64(len(data)-1))
 	}
+	return mean, stdDev
+}
+
+func CalculateBasicAnalysis(data []float64, analysisType, columnName string) *AnalysisResult {
+	count := len(data)
+	var sum float64
+	for _, val := range data {
+		sum += val
+	}
+
+	mean, stdDev := meanAndStdDev(data)
 
 	series := make([]TimeSeriesDataPoint, len(data))
 	for i, val := range data {
@@ -0,0 +1,405 @@
+package analysis
+
+import (
+	"finview/backend/internal/i18n"
+	"math"
+	"strconv"
+	"time"
+)
+
+// ForecastMethod is the model fitted to the monthly net cash flow. The empty
+// method picks Holt-Winters when the history covers two full seasons and the
+// linear trend otherwise.
+type ForecastMethod string
+
+const (
+	ForecastLinear      ForecastMethod = "linear"
+	ForecastHoltWinters ForecastMethod = "holt_winters"
+)
+
+const (
+	DefaultForecastHorizon = 12
+	MaxForecastHorizon     = 60
+
+	// ForecastConfidence is the coverage of the confidence bands and
+	// forecastZ the matching two-sided normal quantile.
+	ForecastConfidence = 0.8
+	forecastZ          = 1.2816
+
+	seasonLength          = 12
+	minLinearMonths       = 3
+	minHoltWintersMonths  = 2 * seasonLength
+	holtWintersSearchStep = 0.05
+)
+
+// MonthlyFlow is an observed month: its net cash flow and closing balance.
+type MonthlyFlow struct {
+	Period  string    `json:"period"`
+	Start   time.Time `json:"start"`
+	NetFlow float64   `json:"net_flow"`
+	Balance float64   `json:"balance"`
+}
+
+// ForecastPoint is a projected month with the bounds of its confidence band.
+type ForecastPoint struct {
+	Period       string    `json:"period"`
+	Start        time.Time `json:"start"`
+	NetFlow      float64   `json:"net_flow"`
+	NetFlowLower float64   `json:"net_flow_lower"`
+	NetFlowUpper float64   `json:"net_flow_upper"`
+	Balance      float64   `json:"balance"`
+	BalanceLower float64   `json:"balance_lower"`
+	BalanceUpper float64   `json:"balance_upper"`
+}
+
+// ForecastModel holds the fitted parameters: slope and intercept of the
+// linear trend, or the smoothing factors of Holt-Winters.
+type ForecastModel struct {
+	Slope     float64 `json:"slope,omitempty"`
+	Intercept float64 `json:"intercept,omitempty"`
+	Alpha     float64 `json:"alpha,omitempty"`
+	Beta      float64 `json:"beta,omitempty"`
+	Gamma     float64 `json:"gamma,omitempty"`
+}
+
+// Forecast projects the balance month by month from the last observed one.
+// ZeroCashMonth is the first month the projected balance reaches zero and
+// EarliestZeroCashMonth the first one its lower bound does; both are empty
+// when the cash lasts beyond the horizon.
+type Forecast struct {
+	Method                ForecastMethod  `json:"method"`
+	Horizon               int             `json:"horizon"`
+	Confidence            float64         `json:"confidence"`
+	Model                 ForecastModel   `json:"model"`
+	ResidualStdDev        float64         `json:"residual_std_dev"`
+	CurrentBalance        float64         `json:"current_balance"`
+	History               []MonthlyFlow   `json:"history"`
+	Points                []ForecastPoint `json:"points"`
+	ZeroCashMonth         string          `json:"zero_cash_month,omitempty"`
+	EarliestZeroCashMonth string          `json:"earliest_zero_cash_month,omitempty"`
+}
+
+func ParseForecastMethod(value string) (ForecastMethod, error) {
+	switch method := ForecastMethod(value); method {
+	case "", ForecastLinear, ForecastHoltWinters:
+		return method, nil
+	}
+	return "", i18n.NewError(i18n.CodeInvalidForecastMethod, i18n.Params{"value": value})
+}
+
+// CalculateForecast fits the monthly net cash flow of a dated series, months
+// without transactions counting as zero, and projects it horizon months
+// ahead. A last month the data stops part way through is kept in the history
+// and the current balance but left out of the fit, see completeMonths. The
+// balance bands add up the variance of each projected month as if their
+// errors were independent.
+func CalculateForecast(series []TimeSeriesDataPoint, openingBalance float64, horizon int, method ForecastMethod) (*Forecast, error) {
+	history := monthlyFlows(series, openingBalance)
+	flows := make([]float64, completeMonths(series))
+	for i := range flows {
+		flows[i] = history[i].NetFlow
+	}
+	// Months between the last fitted one and the first projected one.
+	skipped := len(history) - len(flows)
+
+	if method == "" {
+		method = ForecastLinear
+		if len(flows) >= minHoltWintersMonths {
+			method = ForecastHoltWinters
+		}
+	}
+
+	var fit forecastFit
+	switch method {
+	case ForecastHoltWinters:
+		if len(flows) < minHoltWintersMonths {
+			return nil, insufficientForecastData(len(flows), minHoltWintersMonths)
+		}
+		fit = fitHoltWinters(flows)
+	default:
+		if len(flows) < minLinearMonths {
+			return nil, insufficientForecastData(len(flows), minLinearMonths)
+		}
+		fit = fitLinear(flows)
+	}
+
+	forecast := &Forecast{
+		Method:         method,
+		Horizon:        horizon,
+		Confidence:     ForecastConfidence,
+		Model:          fit.model,
+		ResidualStdDev: fit.sigma,
+		CurrentBalance: history[len(history)-1].Balance,
+		History:        history,
+		Points:         make([]ForecastPoint, horizon),
+	}
+
+	lastStart := history[len(history)-1].Start
+	balance := forecast.CurrentBalance
+	var balanceVariance float64
+	for h := 1; h <= horizon; h++ {
+		net, variance := fit.predict(h + skipped)
+		netMargin := forecastZ * math.Sqrt(variance)
+		balance += net
+		balanceVariance += variance
+		balanceMargin := forecastZ * math.Sqrt(balanceVariance)
+
+		start := lastStart.AddDate(0, h, 0)
+		point := ForecastPoint{
+			Period:       periodLabel(start, GranularityMonth),
+			Start:        start,
+			NetFlow:      net,
+			NetFlowLower: net - netMargin,
+			NetFlowUpper: net + netMargin,
+			Balance:      balance,
+			BalanceLower: balance - balanceMargin,
+			BalanceUpper: balance + balanceMargin,
+		}
+		forecast.Points[h-1] = point
+
+		if forecast.ZeroCashMonth == "" && point.Balance <= 0 {
+			forecast.ZeroCashMonth = point.Period
+		}
+		if forecast.EarliestZeroCashMonth == "" && point.BalanceLower <= 0 {
+			forecast.EarliestZeroCashMonth = point.Period
+		}
+	}
+
+	return forecast, nil
+}
+
+func insufficientForecastData(months, required int) error {
+	return i18n.NewError(i18n.CodeForecastInsufficientData, i18n.Params{
+		"months":   strconv.Itoa(months),
+		"required": strconv.Itoa(required),
+	})
+}
+
+// monthlyFlows sums a series sorted by date into consecutive calendar months.
+func monthlyFlows(series []TimeSeriesDataPoint, openingBalance float64) []MonthlyFlow {
+	if len(series) == 0 {
+		return nil
+	}
+
+	first := periodStart(series[0].Date, GranularityMonth)
+	months := monthIndex(series[len(series)-1].Date) - monthIndex(first) + 1
+	history := make([]MonthlyFlow, months)
+	for i := range history {
+		start := first.AddDate(0, i, 0)
+		history[i] = MonthlyFlow{Period: periodLabel(start, GranularityMonth), Start: start}
+	}
+
+	for _, p := range series {
+		history[monthIndex(p.Date)-monthIndex(first)].NetFlow += p.Value
+	}
+
+	balance := openingBalance
+	for i := range history {
+		balance += history[i].NetFlow
+		history[i].Balance = balance
+	}
+	return history
+}
+
+// partialMonthTolerance is how many days before the usual last day of a
+// month the data may stop and the month still count as complete.
+const partialMonthTolerance = 3
+
+// completeMonths returns how many of the months of monthlyFlows can be fitted
+// as whole months. Only the last month is ever left out: when its last
+// transaction comes more than partialMonthTolerance days before the day the
+// earlier months usually end on, their median last day capped by the length
+// of the month, the data most likely stops part way through it, and fitting
+// it would read as a sudden drop. Pro-rating it instead would also scale up
+// payments that fall early in every month. A series of one month is taken
+// as it is.
+func completeMonths(series []TimeSeriesDataPoint) int {
+	if len(series) == 0 {
+		return 0
+	}
+	first := monthIndex(series[0].Date)
+	last := series[len(series)-1].Date
+	months := monthIndex(last) - first + 1
+
+	lastDays := map[int]int{}
+	for _, p := range series {
+		if m := monthIndex(p.Date); m < monthIndex(last) {
+			lastDays[m] = max(lastDays[m], p.Date.Day())
+		}
+	}
+	if len(lastDays) == 0 {
+		return months
+	}
+	days := make([]float64, 0, len(lastDays))
+	for _, day := range lastDays {
+		days = append(days, float64(day))
+	}
+	daysInMonth := periodStart(last, GranularityMonth).AddDate(0, 1, -1).Day()
+	usual := min(median(days), float64(daysInMonth))
+	if float64(last.Day()+partialMonthTolerance) < usual {
+		return months - 1
+	}
+	return months
+}
+
+// monthTotals are the flows of a calendar month, outflow as a positive
+// amount.
+type monthTotals struct {
+	Period  string
+	Start   time.Time
+	Inflow  float64
+	Outflow float64
+	Net     float64
+}
+
+// monthlyTotals splits the months of monthlyFlows into inflow and outflow.
+func monthlyTotals(series []TimeSeriesDataPoint) []monthTotals {
+	flows := monthlyFlows(series, 0)
+	if len(flows) == 0 {
+		return nil
+	}
+	months := make([]monthTotals, len(flows))
+	for i, flow := range flows {
+		months[i] = monthTotals{Period: flow.Period, Start: flow.Start, Net: flow.NetFlow}
+	}
+	first := monthIndex(flows[0].Start)
+	for _, p := range series {
+		month := &months[monthIndex(p.Date)-first]
+		if p.Value >= 0 {
+			month.Inflow += p.Value
+		} else {
+			month.Outflow += math.Abs(p.Value)
+		}
+	}
+	return months
+}
+
+// forecastFit predicts the net flow h months after the last observed one
+// together with the variance of the prediction.
+type forecastFit struct {
+	model   ForecastModel
+	sigma   float64
+	predict func(h int) (float64, float64)
+}
+
+// fitLinear fits an ordinary least squares trend over the month number. The
+// variance is that of a prediction interval at the extrapolated month.
+func fitLinear(flows []float64) forecastFit {
+	n := float64(len(flows))
+	var meanX, meanY float64
+	for i, y := range flows {
+		meanX += float64(i)
+		meanY += y
+	}
+	meanX /= n
+	meanY /= n
+
+	var sxx, sxy float64
+	for i, y := range flows {
+		dx := float64(i) - meanX
+		sxx += dx * dx
+		sxy += dx * (y - meanY)
+	}
+	slope := sxy / sxx
+	intercept := meanY - slope*meanX
+
+	var sse float64
+	for i, y := range flows {
+		residual := y - (intercept + slope*float64(i))
+		sse += residual * residual
+	}
+	sigma := math.Sqrt(sse / (n - 2))
+
+	return forecastFit{
+		model: ForecastModel{Slope: slope, Intercept: intercept},
+		sigma: sigma,
+		predict: func(h int) (float64, float64) {
+			x := n - 1 + float64(h)
+			variance := sigma * sigma * (1 + 1/n + (x-meanX)*(x-meanX)/sxx)
+			return intercept + slope*x, variance
+		},
+	}
+}
+
+// holtWintersState is the level, trend and seasonal components after
+// smoothing the whole history; seasonal holds the last seasonLength values.
+type holtWintersState struct {
+	level, trend float64
+	seasonal     []float64
+	sse          float64
+	steps        int
+}
+
+// fitHoltWinters fits additive Holt-Winters with a yearly season, choosing
+// the smoothing factors on a grid by the one step ahead squared error.
+func fitHoltWinters(flows []float64) forecastFit {
+	var best holtWintersState
+	var alpha, beta, gamma float64
+	bestSSE := math.Inf(1)
+	steps := int(math.Round(1 / holtWintersSearchStep))
+	for i := 1; i < steps; i++ {
+		for j := 1; j < steps; j++ {
+			for k := 1; k < steps; k++ {
+				a, b, g := float64(i)/float64(steps), float64(j)/float64(steps), float64(k)/float64(steps)
+				state := smoothHoltWinters(flows, a, b, g)
+				if state.sse < bestSSE {
+					best, bestSSE = state, state.sse
+					alpha, beta, gamma = a, b, g
+				}
+			}
+		}
+	}
+	sigma := math.Sqrt(best.sse / float64(best.steps))
+
+	return forecastFit{
+		model: ForecastModel{Alpha: alpha, Beta: beta, Gamma: gamma},
+		sigma: sigma,
+		predict: func(h int) (float64, float64) {
+			// Variance of the additive model's h step ahead forecast.
+			var sum float64
+			for j := 1; j < h; j++ {
+				c := alpha * (1 + float64(j)*beta)
+				if j%seasonLength == 0 {
+					c += gamma
+				}
+				sum += c * c
+			}
+			season := best.seasonal[(h-1)%seasonLength]
+			return best.level + float64(h)*best.trend + season, sigma * sigma * (1 + sum)
+		},
+	}
+}
+
+// smoothHoltWinters runs the additive Holt-Winters recursions. The first
+// season initialises the components: the level is its mean, the trend the
+// change in mean to the second season, and each seasonal index the month's
+// deviation from the level.
+func smoothHoltWinters(flows []float64, alpha, beta, gamma float64) holtWintersState {
+	var firstMean, secondMean float64
+	for i := 0; i < seasonLength; i++ {
+		firstMean += flows[i]
+		secondMean += flows[seasonLength+i]
+	}
+	firstMean /= seasonLength
+	secondMean /= seasonLength
+
+	seasonal := make([]float64, len(flows))
+	for i := 0; i < seasonLength; i++ {
+		seasonal[i] = flows[i] - firstMean
+	}
+	state := holtWintersState{level: firstMean, trend: (secondMean - firstMean) / seasonLength}
+
+	for t := seasonLength; t < len(flows); t++ {
+		previousSeason := seasonal[t-seasonLength]
+		residual := flows[t] - (state.level + state.trend + previousSeason)
+		state.sse += residual * residual
+		state.steps++
+
+		level := alpha*(flows[t]-previousSeason) + (1-alpha)*(state.level+state.trend)
+		state.trend = beta*(level-state.level) + (1-beta)*state.trend
+		state.level = level
+		seasonal[t] = gamma*(flows[t]-level) + (1-gamma)*previousSeason
+	}
+	state.seasonal = seasonal[len(flows)-seasonLength:]
+	return state
+}
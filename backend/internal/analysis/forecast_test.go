@@ -0,0 +1,252 @@
+package analysis
+
+import (
+	"errors"
+	"finview/backend/internal/i18n"
+	"math"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// monthlySeries puts each flow on the 10th of consecutive months from
+// January 2021.
+func monthlySeries(flows []float64) []TimeSeriesDataPoint {
+	series := make([]TimeSeriesDataPoint, len(flows))
+	for i, flow := range flows {
+		series[i] = TimeSeriesDataPoint{Date: date(2021, time.Month(1+i), 10), Value: flow}
+	}
+	return series
+}
+
+var seasonalPattern = []float64{-300, -200, -100, 0, 150, 300, 400, 300, 100, -50, 200, 800}
+
+func TestCalculateForecastLinear(t *testing.T) {
+	flows := make([]float64, 12)
+	for i := range flows {
+		flows[i] = 1000 + 100*float64(i)
+	}
+
+	forecast, err := CalculateForecast(monthlySeries(flows), 500, 3, "")
+	if err != nil {
+		t.Fatalf("CalculateForecast() error = %v", err)
+	}
+	if forecast.Method != ForecastLinear {
+		t.Errorf("method = %s, want %s", forecast.Method, ForecastLinear)
+	}
+	if math.Abs(forecast.Model.Slope-100) > 1e-9 || math.Abs(forecast.Model.Intercept-1000) > 1e-9 {
+		t.Errorf("model = %+v, want slope 100 and intercept 1000", forecast.Model)
+	}
+	if forecast.ResidualStdDev > 1e-9 {
+		t.Errorf("residual std dev = %v, want 0", forecast.ResidualStdDev)
+	}
+	// 500 + 1000 + 1100 + ... + 2100
+	if forecast.CurrentBalance != 19100 {
+		t.Errorf("current balance = %v, want 19100", forecast.CurrentBalance)
+	}
+
+	balance := forecast.CurrentBalance
+	for h, point := range forecast.Points {
+		want := 1000 + 100*float64(12+h)
+		balance += want
+		if math.Abs(point.NetFlow-want) > 1e-6 || math.Abs(point.Balance-balance) > 1e-6 {
+			t.Errorf("point %d = %v / %v, want %v / %v", h, point.NetFlow, point.Balance, want, balance)
+		}
+		if math.Abs(point.NetFlowUpper-point.NetFlowLower) > 1e-6 {
+			t.Errorf("point %d band = %v..%v, want no width on a perfect fit", h, point.NetFlowLower, point.NetFlowUpper)
+		}
+	}
+	if got := forecast.Points[0].Period; got != "2022-01" {
+		t.Errorf("first projected period = %s, want 2022-01", got)
+	}
+}
+
+func TestCalculateForecastLinearBands(t *testing.T) {
+	flows := []float64{900, 1100, 950, 1050, 1000, 980, 1020, 1010}
+	forecast, err := CalculateForecast(monthlySeries(flows), 0, 6, ForecastLinear)
+	if err != nil {
+		t.Fatalf("CalculateForecast() error = %v", err)
+	}
+	previousWidth := 0.0
+	for h, point := range forecast.Points {
+		if point.NetFlowLower >= point.NetFlow || point.NetFlowUpper <= point.NetFlow {
+			t.Errorf("point %d band %v..%v does not contain %v", h, point.NetFlowLower, point.NetFlowUpper, point.NetFlow)
+		}
+		width := point.BalanceUpper - point.BalanceLower
+		if width <= previousWidth {
+			t.Errorf("point %d balance band %v does not widen from %v", h, width, previousWidth)
+		}
+		previousWidth = width
+	}
+}
+
+func TestCalculateForecastHoltWintersSeasonal(t *testing.T) {
+	// A season repeated exactly is fitted without error.
+	flows := make([]float64, 36)
+	for i := range flows {
+		flows[i] = 1000 + seasonalPattern[i%12]
+	}
+
+	forecast, err := CalculateForecast(monthlySeries(flows), 0, 12, "")
+	if err != nil {
+		t.Fatalf("CalculateForecast() error = %v", err)
+	}
+	if forecast.Method != ForecastHoltWinters {
+		t.Fatalf("method = %s, want %s", forecast.Method, ForecastHoltWinters)
+	}
+	if forecast.ResidualStdDev > 1e-9 {
+		t.Errorf("residual std dev = %v, want 0", forecast.ResidualStdDev)
+	}
+	for h, point := range forecast.Points {
+		if want := 1000 + seasonalPattern[h]; math.Abs(point.NetFlow-want) > 1e-6 {
+			t.Errorf("point %s = %v, want %v", point.Period, point.NetFlow, want)
+		}
+	}
+}
+
+func TestCalculateForecastHoltWintersTrend(t *testing.T) {
+	flows := make([]float64, 36)
+	for i := range flows {
+		flows[i] = 1000 + 10*float64(i) + seasonalPattern[i%12]
+	}
+
+	forecast, err := CalculateForecast(monthlySeries(flows), 0, 12, ForecastHoltWinters)
+	if err != nil {
+		t.Fatalf("CalculateForecast() error = %v", err)
+	}
+	for h, point := range forecast.Points {
+		want := 1000 + 10*float64(36+h) + seasonalPattern[h]
+		if math.Abs(point.NetFlow-want) > 100 {
+			t.Errorf("point %s = %v, want about %v", point.Period, point.NetFlow, want)
+		}
+	}
+	// The season survives the projection: December peaks, January bottoms.
+	if !(forecast.Points[11].NetFlow > forecast.Points[6].NetFlow && forecast.Points[6].NetFlow > forecast.Points[0].NetFlow) {
+		t.Errorf("seasonal shape lost: Jan %v, Jul %v, Dec %v", forecast.Points[0].NetFlow, forecast.Points[6].NetFlow, forecast.Points[11].NetFlow)
+	}
+	for _, factor := range []float64{forecast.Model.Alpha, forecast.Model.Beta, forecast.Model.Gamma} {
+		if factor <= 0 || factor >= 1 {
+			t.Errorf("model = %+v, want smoothing factors inside (0, 1)", forecast.Model)
+		}
+	}
+}
+
+func TestCalculateForecastZeroCash(t *testing.T) {
+	flows := []float64{-1000, -1000, -1000, -1000}
+	forecast, err := CalculateForecast(monthlySeries(flows), 6500, 12, "")
+	if err != nil {
+		t.Fatalf("CalculateForecast() error = %v", err)
+	}
+	// 2500 left after April 2021 runs out in July.
+	if forecast.ZeroCashMonth != "2021-07" {
+		t.Errorf("zero cash month = %q, want 2021-07", forecast.ZeroCashMonth)
+	}
+	if forecast.EarliestZeroCashMonth != forecast.ZeroCashMonth {
+		t.Errorf("earliest zero cash month = %q, want %q for a perfect fit", forecast.EarliestZeroCashMonth, forecast.ZeroCashMonth)
+	}
+}
+
+func TestCalculateForecastGapMonths(t *testing.T) {
+	series := []TimeSeriesDataPoint{
+		{Date: date(2024, time.January, 5), Value: 300},
+		{Date: date(2024, time.March, 5), Value: 300},
+		{Date: date(2024, time.March, 20), Value: 300},
+	}
+	forecast, err := CalculateForecast(series, 0, 1, "")
+	if err != nil {
+		t.Fatalf("CalculateForecast() error = %v", err)
+	}
+	var got []float64
+	for _, month := range forecast.History {
+		got = append(got, month.NetFlow)
+	}
+	if len(got) != 3 || got[0] != 300 || got[1] != 0 || got[2] != 600 {
+		t.Errorf("history = %v, want [300 0 600]", got)
+	}
+}
+
+func TestCompleteMonths(t *testing.T) {
+	tests := []struct {
+		name   string
+		series []TimeSeriesDataPoint
+		want   int
+	}{
+		{"one payment a month", monthlySeries([]float64{-100, -100, -100}), 3},
+		{"stops on the 3rd", []TimeSeriesDataPoint{
+			{Date: date(2024, time.January, 28), Value: -100},
+			{Date: date(2024, time.February, 27), Value: -100},
+			{Date: date(2024, time.March, 3), Value: -100},
+		}, 2},
+		{"within the tolerance", []TimeSeriesDataPoint{
+			{Date: date(2024, time.January, 28), Value: -100},
+			{Date: date(2024, time.February, 27), Value: -100},
+			{Date: date(2024, time.March, 25), Value: -100},
+		}, 3},
+		// Ending on the 28th is the end of a short February.
+		{"short month", []TimeSeriesDataPoint{
+			{Date: date(2022, time.December, 31), Value: -100},
+			{Date: date(2023, time.January, 31), Value: -100},
+			{Date: date(2023, time.February, 28), Value: -100},
+		}, 3},
+		{"single month", []TimeSeriesDataPoint{{Date: date(2024, time.March, 3), Value: -100}}, 1},
+		{"empty", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := completeMonths(tt.series); got != tt.want {
+				t.Errorf("completeMonths() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateForecastPartialLastMonth(t *testing.T) {
+	var series []TimeSeriesDataPoint
+	for i := 0; i < 12; i++ {
+		series = append(series,
+			TimeSeriesDataPoint{Date: date(2021, time.Month(1+i), 2), Value: -1000},
+			TimeSeriesDataPoint{Date: date(2021, time.Month(1+i), 28), Value: 2000 + 100*float64(i)},
+		)
+	}
+	// January 2022 has only been seen up to the rent.
+	series = append(series, TimeSeriesDataPoint{Date: date(2022, time.January, 2), Value: -1000})
+
+	forecast, err := CalculateForecast(series, 0, 2, ForecastLinear)
+	if err != nil {
+		t.Fatalf("CalculateForecast() error = %v", err)
+	}
+	if math.Abs(forecast.Model.Slope-100) > 1e-9 || math.Abs(forecast.Model.Intercept-1000) > 1e-9 {
+		t.Errorf("model = %+v, want slope 100 and intercept 1000 from the complete months", forecast.Model)
+	}
+	if len(forecast.History) != 13 || forecast.CurrentBalance != 17600 {
+		t.Errorf("history has %d months and a balance of %v, want 13 and 17600", len(forecast.History), forecast.CurrentBalance)
+	}
+	// January 2022 is the 13th month of the trend, so February is the 14th.
+	if point := forecast.Points[0]; point.Period != "2022-02" || math.Abs(point.NetFlow-2300) > 1e-6 {
+		t.Errorf("first point = %s %v, want 2022-02 2300", point.Period, point.NetFlow)
+	}
+}
+
+func TestCalculateForecastInsufficientData(t *testing.T) {
+	tests := []struct {
+		name   string
+		months int
+		method ForecastMethod
+	}{
+		{"linear", 2, ""},
+		{"holt-winters", 23, ForecastHoltWinters},
+		{"empty", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CalculateForecast(monthlySeries(make([]float64, tt.months)), 0, 12, tt.method)
+			var coded *i18n.Error
+			if !errors.As(err, &coded) || coded.Code != i18n.CodeForecastInsufficientData {
+				t.Errorf("CalculateForecast() error = %v, want %s", err, i18n.CodeForecastInsufficientData)
+			}
+		})
+	}
+}
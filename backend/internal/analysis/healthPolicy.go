@@ -0,0 +1,107 @@
+package analysis
+
+import (
+	"finview/backend/internal/i18n"
+	"strconv"
+)
+
+// Health status codes of the default policy.
+const (
+	HealthInsufficientData = "insufficient_data"
+	HealthCritical         = "critical"
+	HealthAlert            = "alert"
+	HealthHealthy          = "healthy"
+	HealthProfitable       = "profitable"
+	HealthInsolvent        = "insolvent"
+)
+
+// HealthOutcome is a status the policy can assign. Code is the stable value
+// clients switch on; Status and Message are optional labels, taken from the
+// message catalog in the requested language when left empty.
+type HealthOutcome struct {
+	Code    string `json:"code"`
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// HealthBand applies when the runway is shorter than MaxRunwayMonths.
+type HealthBand struct {
+	MaxRunwayMonths float64 `json:"max_runway_months"`
+	HealthOutcome
+}
+
+// HealthPolicy turns a runway into a status. Bands are checked in ascending
+// order of MaxRunwayMonths; a runway past every band is Healthy. Profitable
+// applies when there is no net burn and Insolvent when there is burn but no
+// cash left.
+type HealthPolicy struct {
+	Bands      []HealthBand  `json:"bands"`
+	Healthy    HealthOutcome `json:"healthy"`
+	Profitable HealthOutcome `json:"profitable"`
+	Insolvent  HealthOutcome `json:"insolvent"`
+}
+
+func DefaultHealthPolicy() HealthPolicy {
+	return HealthPolicy{
+		Bands: []HealthBand{
+			{MaxRunwayMonths: 3, HealthOutcome: HealthOutcome{Code: HealthCritical}},
+			{MaxRunwayMonths: 6, HealthOutcome: HealthOutcome{Code: HealthAlert}},
+		},
+		Healthy:    HealthOutcome{Code: HealthHealthy},
+		Profitable: HealthOutcome{Code: HealthProfitable},
+		Insolvent:  HealthOutcome{Code: HealthInsolvent},
+	}
+}
+
+// Validate checks that bands grow and that every outcome has a code, and a
+// status unless the catalog has one for the code.
+func (p HealthPolicy) Validate() error {
+	previous := 0.0
+	for i, band := range p.Bands {
+		if band.MaxRunwayMonths <= previous {
+			return i18n.NewError(i18n.CodeHealthPolicyInvalidBand, i18n.Params{"band": strconv.Itoa(i + 1)})
+		}
+		if err := band.HealthOutcome.validate(); err != nil {
+			return err
+		}
+		previous = band.MaxRunwayMonths
+	}
+	for _, outcome := range []HealthOutcome{p.Healthy, p.Profitable, p.Insolvent} {
+		if err := outcome.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o HealthOutcome) validate() error {
+	if o.Code == "" {
+		return i18n.NewError(i18n.CodeHealthPolicyMissingCode, nil)
+	}
+	if _, ok := i18n.Lookup(i18n.DefaultLanguage, "health_status_"+o.Code, nil); !ok && o.Status == "" {
+		return i18n.NewError(i18n.CodeHealthPolicyMissingStatus, i18n.Params{"code": o.Code})
+	}
+	return nil
+}
+
+// classify returns the outcome for a company that burns cash and still has
+// runwayMonths of it, with the band limit for the message.
+func (p HealthPolicy) classify(runwayMonths float64) (HealthOutcome, i18n.Params) {
+	for _, band := range p.Bands {
+		if runwayMonths < band.MaxRunwayMonths {
+			return band.HealthOutcome, i18n.Params{"months": strconv.FormatFloat(band.MaxRunwayMonths, 'f', -1, 64)}
+		}
+	}
+	return p.Healthy, nil
+}
+
+// localize fills the labels the policy left empty from the catalog.
+func (o HealthOutcome) localize(language string, params i18n.Params) HealthOutcome {
+	if o.Status == "" {
+		o.Status = i18n.T(language, "health_status_"+o.Code, params)
+	}
+	if o.Message == "" {
+		o.Message, _ = i18n.Lookup(language, "health_message_"+o.Code, params)
+	}
+	return o
+}
@@ -0,0 +1,44 @@
+package analysis
+
+import (
+	"errors"
+	"finview/backend/internal/i18n"
+	"testing"
+)
+
+func TestHealthPolicyValidate(t *testing.T) {
+	withBand := func(band HealthBand) HealthPolicy {
+		policy := DefaultHealthPolicy()
+		policy.Bands = append(policy.Bands, band)
+		return policy
+	}
+
+	tests := []struct {
+		name   string
+		policy HealthPolicy
+		code   string
+	}{
+		{"default", DefaultHealthPolicy(), ""},
+		{"standard code without status", withBand(HealthBand{MaxRunwayMonths: 12, HealthOutcome: HealthOutcome{Code: HealthAlert}}), ""},
+		{"custom code with status", withBand(HealthBand{MaxRunwayMonths: 12, HealthOutcome: HealthOutcome{Code: "watch", Status: "Watch"}}), ""},
+		{"custom code without status", withBand(HealthBand{MaxRunwayMonths: 12, HealthOutcome: HealthOutcome{Code: "watch"}}), i18n.CodeHealthPolicyMissingStatus},
+		{"band without code", withBand(HealthBand{MaxRunwayMonths: 12}), i18n.CodeHealthPolicyMissingCode},
+		{"bands out of order", withBand(HealthBand{MaxRunwayMonths: 2, HealthOutcome: HealthOutcome{Code: HealthAlert}}), i18n.CodeHealthPolicyInvalidBand},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			var coded *i18n.Error
+			if !errors.As(err, &coded) || coded.Code != tt.code {
+				t.Fatalf("Validate() = %v, want code %s", err, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package analysis
+
+import (
+	"finview/backend/internal/i18n"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Granularity is the period length the series are bucketed by. The empty
+// granularity keeps one point per transaction.
+type Granularity string
+
+const (
+	GranularityDay     Granularity = "day"
+	GranularityWeek    Granularity = "week"
+	GranularityMonth   Granularity = "month"
+	GranularityQuarter Granularity = "quarter"
+	GranularityYear    Granularity = "year"
+)
+
+type PeriodSummary struct {
+	Period         string    `json:"period"`
+	Start          time.Time `json:"start"`
+	Count          int       `json:"count"`
+	Inflow         float64   `json:"inflow"`
+	Outflow        float64   `json:"outflow"`
+	Net            float64   `json:"net"`
+	ClosingBalance float64   `json:"closing_balance"`
+}
+
+func ParseGranularity(value string) (Granularity, error) {
+	switch granularity := Granularity(value); granularity {
+	case "", GranularityDay, GranularityWeek, GranularityMonth, GranularityQuarter, GranularityYear:
+		return granularity, nil
+	}
+	return "", i18n.NewError(i18n.CodeInvalidGranularity, i18n.Params{"value": value})
+}
+
+// AggregateByPeriod replaces the per-transaction Series and BalanceSeries of
+// a time series analysis with one point per period: Series carries the net
+// flow of the period and BalanceSeries its closing balance. The full
+// breakdown goes to Periods. Statistics and health are left as computed from
+// the individual transactions.
+func AggregateByPeriod(result *AnalysisResult, granularity Granularity) {
+	if granularity == "" {
+		return
+	}
+	result.Granularity = granularity
+	result.Periods = []PeriodSummary{}
+
+	for i, p := range result.Series {
+		start := periodStart(p.Date, granularity)
+
+		last := len(result.Periods) - 1
+		if last == -1 || !result.Periods[last].Start.Equal(start) {
+			result.Periods = append(result.Periods, PeriodSummary{
+				Period: periodLabel(start, granularity),
+				Start:  start,
+			})
+			last++
+		}
+
+		period := &result.Periods[last]
+		period.Count++
+		if p.Value >= 0 {
+			period.Inflow += p.Value
+		} else {
+			period.Outflow += math.Abs(p.Value)
+		}
+		period.Net += p.Value
+		if i < len(result.BalanceSeries) {
+			period.ClosingBalance = result.BalanceSeries[i].Value
+		}
+	}
+
+	result.Series = make([]TimeSeriesDataPoint, len(result.Periods))
+	result.BalanceSeries = make([]TimeSeriesDataPoint, len(result.Periods))
+	for i, period := range result.Periods {
+		result.Series[i] = TimeSeriesDataPoint{Date: period.Start, Value: period.Net}
+		result.BalanceSeries[i] = TimeSeriesDataPoint{Date: period.Start, Value: period.ClosingBalance}
+	}
+}
+
+// periodStart returns the first day of the period holding t. Weeks start on
+// Monday, as in ISO 8601.
+func periodStart(t time.Time, granularity Granularity) time.Time {
+	year, month, day := t.Date()
+	switch granularity {
+	case GranularityWeek:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, t.Location())
+	case GranularityMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	case GranularityQuarter:
+		return time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, t.Location())
+	case GranularityYear:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+func periodLabel(start time.Time, granularity Granularity) string {
+	switch granularity {
+	case GranularityWeek:
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case GranularityMonth:
+		return start.Format("2006-01")
+	case GranularityQuarter:
+		return fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())-1)/3+1)
+	case GranularityYear:
+		return start.Format("2006")
+	}
+	return start.Format("2006-01-02")
+}
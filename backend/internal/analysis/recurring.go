@@ -0,0 +1,196 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// AnalysisRecurring is the analysis type that lists the recurring streams.
+const AnalysisRecurring = "recurring"
+
+// Cadences a recurring stream can follow.
+const (
+	CadenceWeekly  = "weekly"
+	CadenceMonthly = "monthly"
+	CadenceAnnual  = "annual"
+)
+
+// cadence describes how often a stream repeats: Days is the typical gap and
+// Tolerance how far from it a gap may be, both in days. MonthlyFactor turns
+// one occurrence into a monthly amount.
+type cadence struct {
+	Name          string
+	Days          float64
+	Tolerance     float64
+	MinCount      int
+	MonthlyFactor float64
+	next          func(time.Time) time.Time
+}
+
+var cadences = []cadence{
+	{CadenceWeekly, 7, 2, 4, 52.0 / 12, func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }},
+	{CadenceMonthly, 30.4, 5, 3, 1, func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+	{CadenceAnnual, 365.25, 20, 2, 1.0 / 12, func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }},
+}
+
+const (
+	// Share of the gaps that must match the cadence, so one late or early
+	// payment does not break a stream.
+	minCadenceMatch = 0.75
+	// Largest coefficient of variation of the amounts of a stream.
+	maxAmountVariation = 0.25
+)
+
+// RecurringStream is a payment or receipt that repeats at a cadence. A
+// stream is Missed when its next expected date, plus the cadence tolerance,
+// is before the last date of the series; MonthlyAmount is its average amount
+// converted to a month.
+type RecurringStream struct {
+	Description      string    `json:"description"`
+	Category         string    `json:"category,omitempty"`
+	Direction        string    `json:"direction"`
+	Cadence          string    `json:"cadence"`
+	Occurrences      int       `json:"occurrences"`
+	AverageAmount    float64   `json:"average_amount"`
+	MonthlyAmount    float64   `json:"monthly_amount"`
+	FirstDate        time.Time `json:"first_date"`
+	LastDate         time.Time `json:"last_date"`
+	NextExpectedDate time.Time `json:"next_expected_date"`
+	Missed           bool      `json:"missed"`
+}
+
+// RecurringReport lists the recurring streams with the fixed burn they add
+// up to, the same figure reported in the health section.
+type RecurringReport struct {
+	Streams   []RecurringStream `json:"streams"`
+	FixedBurn float64           `json:"fixed_burn"`
+}
+
+// DetectRecurring groups a series sorted by date by description, ignoring
+// case, digits and punctuation, or by category and amount when there is no
+// description, and keeps the groups whose gaps follow a cadence and whose
+// amounts stay close. Streams are sorted by the size of their monthly
+// amount. Projected points, such as the forecast months of a scenario, are
+// not payments and are skipped, so they neither form a stream nor move the
+// date streams are checked against.
+func DetectRecurring(series []TimeSeriesDataPoint) []RecurringStream {
+	streams := []RecurringStream{}
+	var observed []TimeSeriesDataPoint
+	for _, p := range series {
+		if p.Value != 0 && p.Metadata["projected"] != "true" {
+			observed = append(observed, p)
+		}
+	}
+	if len(observed) == 0 {
+		return streams
+	}
+	asOf := observed[len(observed)-1].Date
+
+	groups := map[string][]TimeSeriesDataPoint{}
+	var keys []string
+	for _, p := range observed {
+		key := flowDirection(p.Value) + "|" + recurringKey(p)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], p)
+	}
+
+	for _, key := range keys {
+		if stream, ok := detectStream(groups[key], asOf); ok {
+			streams = append(streams, stream)
+		}
+	}
+	sort.SliceStable(streams, func(i, j int) bool {
+		return math.Abs(streams[i].MonthlyAmount) > math.Abs(streams[j].MonthlyAmount)
+	})
+	return streams
+}
+
+// FixedBurn is the monthly outflow of the recurring streams that are still
+// being paid, as a positive amount.
+func FixedBurn(streams []RecurringStream) float64 {
+	var burn float64
+	for _, s := range streams {
+		if s.Direction == "outflow" && !s.Missed {
+			burn += math.Abs(s.MonthlyAmount)
+		}
+	}
+	return burn
+}
+
+func detectStream(points []TimeSeriesDataPoint, asOf time.Time) (RecurringStream, bool) {
+	if len(points) < 2 {
+		return RecurringStream{}, false
+	}
+
+	gaps := make([]float64, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		gaps[i-1] = points[i].Date.Sub(points[i-1].Date).Hours() / 24
+	}
+	typicalGap := median(gaps)
+
+	for _, c := range cadences {
+		if len(points) < c.MinCount || math.Abs(typicalGap-c.Days) > c.Tolerance {
+			continue
+		}
+
+		var matching int
+		for _, gap := range gaps {
+			if math.Abs(gap-c.Days) <= c.Tolerance {
+				matching++
+			}
+		}
+		if float64(matching) < minCadenceMatch*float64(len(gaps)) {
+			return RecurringStream{}, false
+		}
+
+		amounts := make([]float64, len(points))
+		for i, p := range points {
+			amounts[i] = p.Value
+		}
+		mean, stdDev := meanAndStdDev(amounts)
+		if stdDev/math.Abs(mean) > maxAmountVariation {
+			return RecurringStream{}, false
+		}
+
+		last := points[len(points)-1]
+		next := c.next(last.Date)
+		return RecurringStream{
+			Description:      last.Description,
+			Category:         last.Category,
+			Direction:        flowDirection(mean),
+			Cadence:          c.Name,
+			Occurrences:      len(points),
+			AverageAmount:    mean,
+			MonthlyAmount:    mean * c.MonthlyFactor,
+			FirstDate:        points[0].Date,
+			LastDate:         last.Date,
+			NextExpectedDate: next,
+			Missed:           asOf.Sub(next).Hours()/24 > c.Tolerance,
+		}, true
+	}
+	return RecurringStream{}, false
+}
+
+// recurringKey identifies the stream a point may belong to. Digits are
+// dropped from descriptions because banks add dates and document numbers.
+func recurringKey(p TimeSeriesDataPoint) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(p.Description) {
+		switch {
+		case unicode.IsLetter(r):
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || unicode.IsPunct(r):
+			b.WriteRune(' ')
+		}
+	}
+	if description := strings.Join(strings.Fields(b.String()), " "); description != "" {
+		return "description|" + description
+	}
+	return "amount|" + strings.ToLower(p.Category) + "|" + strconv.FormatFloat(math.Round(p.Value*100)/100, 'f', 2, 64)
+}
@@ -0,0 +1,189 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"testing"
+	"time"
+)
+
+func sortedByDate(series []TimeSeriesDataPoint) []TimeSeriesDataPoint {
+	sort.SliceStable(series, func(i, j int) bool {
+		return series[i].Date.Before(series[j].Date)
+	})
+	return series
+}
+
+func TestDetectRecurringCadences(t *testing.T) {
+	var series []TimeSeriesDataPoint
+	for m := 0; m < 6; m++ {
+		// Paid on slightly different days and with a document number that
+		// changes every month.
+		day := 5 + m%3
+		series = append(series, TimeSeriesDataPoint{
+			Date:        date(2024, time.Month(1+m), day),
+			Value:       -3000 - float64(m%2)*50,
+			Category:    "Rent",
+			Description: "ALUGUEL DOC 00" + string(rune('1'+m)),
+		})
+	}
+	for w := 0; w < 26; w++ {
+		series = append(series, TimeSeriesDataPoint{
+			Date:     date(2024, time.January, 1).AddDate(0, 0, 7*w),
+			Value:    -120,
+			Category: "Cleaning",
+		})
+	}
+	series = append(series,
+		TimeSeriesDataPoint{Date: date(2023, time.February, 1), Value: -600, Description: "Domain renewal"},
+		TimeSeriesDataPoint{Date: date(2024, time.February, 3), Value: -620, Description: "domain renewal!"},
+	)
+
+	streams := DetectRecurring(sortedByDate(series))
+
+	want := []struct {
+		cadence     string
+		occurrences int
+		average     float64
+		monthly     float64
+	}{
+		{CadenceMonthly, 6, -3025, -3025},
+		{CadenceWeekly, 26, -120, -120 * 52.0 / 12},
+		{CadenceAnnual, 2, -610, -610.0 / 12},
+	}
+	if len(streams) != len(want) {
+		t.Fatalf("got %d streams, want %d: %+v", len(streams), len(want), streams)
+	}
+	for i, w := range want {
+		got := streams[i]
+		if got.Cadence != w.cadence || got.Occurrences != w.occurrences ||
+			math.Abs(got.AverageAmount-w.average) > 1e-9 || math.Abs(got.MonthlyAmount-w.monthly) > 1e-9 {
+			t.Errorf("stream %d = %s x%d avg %v monthly %v, want %s x%d avg %v monthly %v",
+				i, got.Cadence, got.Occurrences, got.AverageAmount, got.MonthlyAmount, w.cadence, w.occurrences, w.average, w.monthly)
+		}
+		if got.Direction != "outflow" || got.Missed {
+			t.Errorf("stream %d = %s missed %v, want an active outflow", i, got.Direction, got.Missed)
+		}
+	}
+
+	if got, want := streams[0].NextExpectedDate, date(2024, time.July, 7); !got.Equal(want) {
+		t.Errorf("next rent = %v, want %v", got, want)
+	}
+	if got, want := streams[1].NextExpectedDate, date(2024, time.July, 1); !got.Equal(want) {
+		t.Errorf("next cleaning = %v, want %v", got, want)
+	}
+}
+
+func TestDetectRecurringAmountTolerance(t *testing.T) {
+	build := func(amounts []float64) []TimeSeriesDataPoint {
+		var series []TimeSeriesDataPoint
+		for m, amount := range amounts {
+			series = append(series, TimeSeriesDataPoint{Date: date(2024, time.Month(1+m), 10), Value: amount, Description: "Supplier"})
+		}
+		return series
+	}
+
+	if streams := DetectRecurring(build([]float64{-1000, -1100, -950, -1050})); len(streams) != 1 {
+		t.Errorf("got %d streams for close amounts, want 1", len(streams))
+	}
+	// A standard deviation above a quarter of the mean is not a fixed cost.
+	if streams := DetectRecurring(build([]float64{-200, -1500, -600, -2500})); len(streams) != 0 {
+		t.Errorf("got %+v for scattered amounts, want none", streams)
+	}
+}
+
+func TestDetectRecurringIrregularGaps(t *testing.T) {
+	series := []TimeSeriesDataPoint{
+		{Date: date(2024, time.January, 1), Value: -80, Description: "Courier"},
+		{Date: date(2024, time.January, 3), Value: -80, Description: "Courier"},
+		{Date: date(2024, time.February, 20), Value: -80, Description: "Courier"},
+		{Date: date(2024, time.April, 2), Value: -80, Description: "Courier"},
+		{Date: date(2024, time.April, 9), Value: -80, Description: "Courier"},
+	}
+	if streams := DetectRecurring(series); len(streams) != 0 {
+		t.Errorf("got %+v, want no stream for irregular gaps", streams)
+	}
+}
+
+func TestDetectRecurringMissedAndFixedBurn(t *testing.T) {
+	var series []TimeSeriesDataPoint
+	for m := 0; m < 12; m++ {
+		series = append(series,
+			TimeSeriesDataPoint{Date: date(2024, time.Month(1+m), 5), Value: -2000, Description: "Payroll"},
+			TimeSeriesDataPoint{Date: date(2024, time.Month(1+m), 15), Value: 9000, Description: "Retainer ACME"},
+		)
+		if m < 6 {
+			series = append(series, TimeSeriesDataPoint{Date: date(2024, time.Month(1+m), 8), Value: -300, Description: "Old software"})
+		}
+	}
+
+	streams := DetectRecurring(sortedByDate(series))
+	if len(streams) != 3 {
+		t.Fatalf("got %d streams, want 3: %+v", len(streams), streams)
+	}
+
+	byDescription := map[string]RecurringStream{}
+	for _, s := range streams {
+		byDescription[s.Description] = s
+	}
+	if s := byDescription["Old software"]; !s.Missed {
+		t.Errorf("old software = %+v, want it missed after June", s)
+	}
+	if s := byDescription["Retainer ACME"]; s.Direction != "inflow" || s.Missed {
+		t.Errorf("retainer = %+v, want an active inflow", s)
+	}
+
+	// Only the payroll is still being paid out.
+	if got := FixedBurn(streams); got != 2000 {
+		t.Errorf("fixed burn = %v, want 2000", got)
+	}
+}
+
+func TestCalculateTimeSeriesAnalysisFixedBurn(t *testing.T) {
+	var series []TimeSeriesDataPoint
+	for m := 0; m < 6; m++ {
+		series = append(series,
+			TimeSeriesDataPoint{Date: date(2024, time.Month(1+m), 5), Value: -2000, Description: "Payroll"},
+			TimeSeriesDataPoint{Date: date(2024, time.Month(1+m), 20), Value: -float64(300 + 400*(m%3)), Description: "Ad hoc " + string(rune('A'+m))},
+		)
+	}
+
+	result := CalculateTimeSeriesAnalysis(series, AnalysisRecurring, "", TimeSeriesOptions{OpeningBalance: 50000})
+
+	if result.Recurring == nil || len(result.Recurring.Streams) != 1 {
+		t.Fatalf("recurring = %+v, want the payroll stream", result.Recurring)
+	}
+	if result.Health.FixedBurn != 2000 || result.Recurring.FixedBurn != 2000 {
+		t.Errorf("fixed burn = %v / %v, want 2000", result.Health.FixedBurn, result.Recurring.FixedBurn)
+	}
+	if want := result.Health.GrossBurn - 2000; math.Abs(result.Health.VariableBurn-want) > 1e-9 {
+		t.Errorf("variable burn = %v, want %v", result.Health.VariableBurn, want)
+	}
+
+	other := CalculateTimeSeriesAnalysis(series, "summary", "", TimeSeriesOptions{OpeningBalance: 50000})
+	if other.Recurring != nil || other.Health.FixedBurn != 2000 {
+		t.Errorf("other analysis types get fixed burn %v and recurring %+v, want 2000 and none", other.Health.FixedBurn, other.Recurring)
+	}
+}
+
+func TestDetectRecurringSkipsProjectedPoints(t *testing.T) {
+	var series []TimeSeriesDataPoint
+	for m := time.January; m <= time.December; m++ {
+		series = append(series,
+			TimeSeriesDataPoint{Date: date(2024, m, 5), Value: 1000, Description: "Sales"},
+			TimeSeriesDataPoint{Date: date(2024, m, 10), Value: -3000, Description: "Rent"},
+		)
+	}
+	comparison := CompareScenario(series, nil, ScenarioOptions{OpeningBalance: 50000, HorizonMonths: 12})
+
+	// The flat projected net flow of -2000 a month is not a payment, and
+	// the rent is still due after the last real one.
+	if health := comparison.Baseline.Health; health.FixedBurn != 3000 {
+		t.Errorf("fixed burn = %v, want the 3000 of rent", health.FixedBurn)
+	}
+	projection, _ := projectMonthlyFlows(series, 12)
+	streams := DetectRecurring(append(series, projection...))
+	if len(streams) != 2 || streams[0].Description != "Rent" || streams[0].Missed {
+		t.Errorf("streams = %+v, want the rent and the sales, none missed", streams)
+	}
+}
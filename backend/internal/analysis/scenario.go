@@ -0,0 +1,328 @@
+package analysis
+
+import (
+	"finview/backend/internal/i18n"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of scenario adjustment.
+const (
+	AdjustmentOneOff             = "one_off"
+	AdjustmentRecurring          = "recurring"
+	AdjustmentCategoryPercentage = "category_percentage"
+)
+
+const (
+	DefaultScenarioHorizon = 12
+	MaxScenarioHorizon     = 60
+)
+
+// ScenarioAdjustment changes the cash flow of a what-if scenario:
+//   - one_off adds Amount on Date;
+//   - recurring adds Amount every month on the day of Start, up to End or the
+//     end of the scenario horizon;
+//   - category_percentage scales the flows of Category by Percent, -20
+//     cutting them by a fifth, optionally only between Start and End.
+type ScenarioAdjustment struct {
+	Type        string     `json:"type"`
+	Description string     `json:"description,omitempty"`
+	Amount      float64    `json:"amount,omitempty"`
+	Date        *time.Time `json:"date,omitempty"`
+	Start       *time.Time `json:"start,omitempty"`
+	End         *time.Time `json:"end,omitempty"`
+	Category    string     `json:"category,omitempty"`
+	Percent     float64    `json:"percent,omitempty"`
+}
+
+// Validate checks the fields each kind of adjustment needs. Errors name the
+// adjustment by its position, counting from one.
+func (a ScenarioAdjustment) Validate(index int) error {
+	params := i18n.Params{"adjustment": strconv.Itoa(index + 1)}
+	switch a.Type {
+	case AdjustmentOneOff:
+		if a.Date == nil {
+			return i18n.NewError(i18n.CodeAdjustmentDateRequired, params)
+		}
+		if a.Amount == 0 {
+			return i18n.NewError(i18n.CodeAdjustmentAmountRequired, params)
+		}
+	case AdjustmentRecurring:
+		if a.Start == nil {
+			return i18n.NewError(i18n.CodeAdjustmentStartRequired, params)
+		}
+		if a.Amount == 0 {
+			return i18n.NewError(i18n.CodeAdjustmentAmountRequired, params)
+		}
+	case AdjustmentCategoryPercentage:
+		if strings.TrimSpace(a.Category) == "" {
+			return i18n.NewError(i18n.CodeAdjustmentCategoryRequired, params)
+		}
+		if a.Percent < -100 {
+			return i18n.NewError(i18n.CodeAdjustmentInvalidPercent, params)
+		}
+	default:
+		params["value"] = a.Type
+		return i18n.NewError(i18n.CodeAdjustmentInvalidType, params)
+	}
+	if a.Start != nil && a.End != nil && a.End.Before(*a.Start) {
+		return i18n.NewError(i18n.CodeAdjustmentInvalidPeriod, params)
+	}
+	return nil
+}
+
+// ScenarioOptions configure CompareScenario. HorizonMonths is how far past
+// the last observed date both timelines are projected; the remaining fields
+// are passed to CalculateTimeSeriesAnalysis.
+type ScenarioOptions struct {
+	OpeningBalance   float64
+	HorizonMonths    int
+	BurnWindowMonths int
+	HealthPolicy     *HealthPolicy
+	Language         string
+}
+
+// ScenarioOutcome is one side of a comparison. ZeroCashDate is the first
+// date the balance reaches zero, empty when it never does.
+type ScenarioOutcome struct {
+	BalanceSeries  []TimeSeriesDataPoint `json:"balance_series"`
+	FlowSummary    CashFlowSummary       `json:"flow_summary"`
+	Health         FinancialHealth       `json:"health"`
+	ClosingBalance float64               `json:"closing_balance"`
+	ZeroCashDate   string                `json:"zero_cash_date,omitempty"`
+}
+
+// ScenarioComparison puts the scenario next to the baseline over the same
+// timeline. ProjectionMethod is empty when the history is too short to
+// forecast and both timelines stop at the last observed date.
+type ScenarioComparison struct {
+	HorizonMonths       int             `json:"horizon_months"`
+	ProjectionMethod    ForecastMethod  `json:"projection_method,omitempty"`
+	ProjectedFrom       string          `json:"projected_from,omitempty"`
+	Baseline            ScenarioOutcome `json:"baseline"`
+	Scenario            ScenarioOutcome `json:"scenario"`
+	ClosingBalanceDelta float64         `json:"closing_balance_delta"`
+}
+
+// CompareScenario layers adjustments onto a dated series. Both timelines are
+// extended HorizonMonths past the last observed date with the monthly net
+// flow forecast of the history, one point on the first day of each month.
+// When the history is too short to forecast, adjustments after the last
+// observed date are left out.
+// Category changes apply to the history and, through the average monthly
+// flow of the category, to the projected months; recurring and one-off
+// amounts are added once on top, so none of them bend the trend. Health is
+// evaluated at the end of each timeline.
+func CompareScenario(series []TimeSeriesDataPoint, adjustments []ScenarioAdjustment, options ScenarioOptions) *ScenarioComparison {
+	history := append([]TimeSeriesDataPoint(nil), series...)
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Date.Before(history[j].Date)
+	})
+
+	comparison := &ScenarioComparison{HorizonMonths: options.HorizonMonths}
+
+	// Without history the horizon starts today.
+	lastDate := time.Now()
+	if len(history) > 0 {
+		lastDate = history[len(history)-1].Date
+	}
+	horizonEnd := periodStart(lastDate, GranularityMonth).AddDate(0, options.HorizonMonths+1, -1)
+
+	projection, method := projectMonthlyFlows(history, options.HorizonMonths)
+	if method != "" {
+		comparison.ProjectionMethod = method
+		comparison.ProjectedFrom = projection[0].Date.Format("2006-01-02")
+	} else {
+		// Nothing is projected, so the adjustments stop where the baseline
+		// does and both outcomes are read on the same date.
+		horizonEnd = lastDate
+	}
+
+	baseline := append(append([]TimeSeriesDataPoint(nil), history...), projection...)
+	scenario := applyCategoryChanges(history, adjustments)
+	scenario = append(scenario, projection...)
+	scenario = append(scenario, projectedCategoryChanges(history, projection, adjustments)...)
+	scenario = append(scenario, recurringPoints(adjustments, time.Time{}, horizonEnd)...)
+	scenario = append(scenario, oneOffPoints(adjustments, horizonEnd)...)
+
+	comparison.Baseline = scenarioOutcome(baseline, options)
+	comparison.Scenario = scenarioOutcome(scenario, options)
+	comparison.ClosingBalanceDelta = comparison.Scenario.ClosingBalance - comparison.Baseline.ClosingBalance
+	return comparison
+}
+
+// projectMonthlyFlows forecasts the net flow of the months after the series
+// as points on the first day of each month. It returns no points when the
+// series is too short to fit a forecast.
+func projectMonthlyFlows(series []TimeSeriesDataPoint, horizon int) ([]TimeSeriesDataPoint, ForecastMethod) {
+	if horizon <= 0 || len(series) == 0 {
+		return nil, ""
+	}
+	sorted := append([]TimeSeriesDataPoint(nil), series...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	forecast, err := CalculateForecast(sorted, 0, horizon, "")
+	if err != nil {
+		return nil, ""
+	}
+	points := make([]TimeSeriesDataPoint, len(forecast.Points))
+	for i, p := range forecast.Points {
+		points[i] = TimeSeriesDataPoint{
+			Date:     p.Start,
+			Value:    p.NetFlow,
+			Metadata: map[string]string{"projected": "true"},
+		}
+	}
+	return points, forecast.Method
+}
+
+// applyCategoryChanges returns a copy of series with the category_percentage
+// adjustments applied. Category names are compared ignoring case.
+func applyCategoryChanges(series []TimeSeriesDataPoint, adjustments []ScenarioAdjustment) []TimeSeriesDataPoint {
+	changed := append([]TimeSeriesDataPoint(nil), series...)
+	for _, a := range adjustments {
+		if a.Type != AdjustmentCategoryPercentage {
+			continue
+		}
+		for i, p := range changed {
+			if !strings.EqualFold(p.Category, a.Category) || !within(p.Date, a.Start, a.End) {
+				continue
+			}
+			changed[i].Value = p.Value * (1 + a.Percent/100)
+		}
+	}
+	return changed
+}
+
+// projectedCategoryChanges lists what the category_percentage adjustments
+// change in the projected months, one point per month on the day of the
+// projection. The projected flow of a category is its average monthly flow
+// in the history, months without transactions counting as zero. An
+// adjustment applies to a month when its period touches the month.
+func projectedCategoryChanges(history, projection []TimeSeriesDataPoint, adjustments []ScenarioAdjustment) []TimeSeriesDataPoint {
+	months := len(monthlyFlows(history, 0))
+	if months == 0 {
+		return nil
+	}
+
+	var points []TimeSeriesDataPoint
+	for _, a := range adjustments {
+		if a.Type != AdjustmentCategoryPercentage {
+			continue
+		}
+		var total float64
+		for _, p := range history {
+			if strings.EqualFold(p.Category, a.Category) {
+				total += p.Value
+			}
+		}
+		change := total / float64(months) * a.Percent / 100
+		if change == 0 {
+			continue
+		}
+
+		var start *time.Time
+		if a.Start != nil {
+			month := periodStart(*a.Start, GranularityMonth)
+			start = &month
+		}
+		for _, p := range projection {
+			if !within(p.Date, start, a.End) {
+				continue
+			}
+			points = append(points, TimeSeriesDataPoint{
+				Date:        p.Date,
+				Value:       change,
+				Category:    a.Category,
+				Description: a.Description,
+				Metadata:    map[string]string{"projected": "true"},
+			})
+		}
+	}
+	return points
+}
+
+// recurringPoints lists the occurrences of the recurring adjustments after
+// from and up to until. Months shorter than the start day get the amount on
+// their last day.
+func recurringPoints(adjustments []ScenarioAdjustment, from, until time.Time) []TimeSeriesDataPoint {
+	var points []TimeSeriesDataPoint
+	for _, a := range adjustments {
+		if a.Type != AdjustmentRecurring {
+			continue
+		}
+		last := until
+		if a.End != nil && a.End.Before(last) {
+			last = *a.End
+		}
+
+		day := a.Start.Day()
+		month := periodStart(*a.Start, GranularityMonth)
+		for ; !month.After(last); month = month.AddDate(0, 1, 0) {
+			daysInMonth := month.AddDate(0, 1, -1).Day()
+			date := month.AddDate(0, 0, min(day, daysInMonth)-1)
+			if !date.After(from) || date.After(last) {
+				continue
+			}
+			points = append(points, TimeSeriesDataPoint{
+				Date:        date,
+				Value:       a.Amount,
+				Description: a.Description,
+			})
+		}
+	}
+	return points
+}
+
+// oneOffPoints lists the one_off adjustments dated up to until.
+func oneOffPoints(adjustments []ScenarioAdjustment, until time.Time) []TimeSeriesDataPoint {
+	var points []TimeSeriesDataPoint
+	for _, a := range adjustments {
+		if a.Type != AdjustmentOneOff || a.Date.After(until) {
+			continue
+		}
+		points = append(points, TimeSeriesDataPoint{
+			Date:        *a.Date,
+			Value:       a.Amount,
+			Description: a.Description,
+		})
+	}
+	return points
+}
+
+func within(date time.Time, start, end *time.Time) bool {
+	if start != nil && date.Before(*start) {
+		return false
+	}
+	return end == nil || !date.After(*end)
+}
+
+func scenarioOutcome(series []TimeSeriesDataPoint, options ScenarioOptions) ScenarioOutcome {
+	result := CalculateTimeSeriesAnalysis(series, "scenario", "", TimeSeriesOptions{
+		OpeningBalance:   options.OpeningBalance,
+		BurnWindowMonths: options.BurnWindowMonths,
+		HealthPolicy:     options.HealthPolicy,
+		Language:         options.Language,
+	})
+
+	outcome := ScenarioOutcome{
+		BalanceSeries:  result.BalanceSeries,
+		FlowSummary:    result.FlowSummary,
+		Health:         result.Health,
+		ClosingBalance: options.OpeningBalance,
+	}
+	if n := len(result.BalanceSeries); n > 0 {
+		outcome.ClosingBalance = result.BalanceSeries[n-1].Value
+	}
+	for _, p := range result.BalanceSeries {
+		if p.Value <= 0 {
+			outcome.ZeroCashDate = p.Date.Format("2006-01-02")
+			break
+		}
+	}
+	return outcome
+}
@@ -0,0 +1,169 @@
+package analysis
+
+import (
+	"errors"
+	"finview/backend/internal/i18n"
+	"math"
+	"testing"
+	"time"
+)
+
+func datePtr(year int, month time.Month, day int) *time.Time {
+	d := date(year, month, day)
+	return &d
+}
+
+// scenarioHistory is a year of 5000 of sales and 1000 of rent each month.
+func scenarioHistory() []TimeSeriesDataPoint {
+	var series []TimeSeriesDataPoint
+	for m := time.January; m <= time.December; m++ {
+		series = append(series,
+			TimeSeriesDataPoint{Date: date(2024, m, 5), Value: 5000, Category: "Sales"},
+			TimeSeriesDataPoint{Date: date(2024, m, 10), Value: -1000, Category: "Rent"},
+		)
+	}
+	return series
+}
+
+func TestCompareScenarioDelta(t *testing.T) {
+	tests := []struct {
+		name        string
+		horizon     int
+		adjustments []ScenarioAdjustment
+		want        float64
+	}{
+		{
+			name:    "recurring starting inside the history",
+			horizon: 6,
+			adjustments: []ScenarioAdjustment{
+				{Type: AdjustmentRecurring, Amount: -1000, Start: datePtr(2024, time.July, 15)},
+			},
+			// July 2024 to June 2025.
+			want: -12000,
+		},
+		{
+			name:    "recurring starting after the history",
+			horizon: 12,
+			adjustments: []ScenarioAdjustment{
+				{Type: AdjustmentRecurring, Amount: -1000, Start: datePtr(2025, time.March, 1), End: datePtr(2025, time.May, 31)},
+			},
+			want: -3000,
+		},
+		{
+			name:    "category change after the history",
+			horizon: 12,
+			adjustments: []ScenarioAdjustment{
+				{Type: AdjustmentCategoryPercentage, Category: "rent", Percent: -50, Start: datePtr(2025, time.January, 15)},
+			},
+			want: 6000,
+		},
+		{
+			name:    "category change over the whole timeline",
+			horizon: 12,
+			adjustments: []ScenarioAdjustment{
+				{Type: AdjustmentCategoryPercentage, Category: "Rent", Percent: -50},
+			},
+			want: 12000,
+		},
+		{
+			name:    "one-off inside the horizon",
+			horizon: 3,
+			adjustments: []ScenarioAdjustment{
+				{Type: AdjustmentOneOff, Amount: 2500, Date: datePtr(2025, time.February, 20)},
+				{Type: AdjustmentOneOff, Amount: 9999, Date: datePtr(2026, time.January, 1)},
+			},
+			want: 2500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparison := CompareScenario(scenarioHistory(), tt.adjustments, ScenarioOptions{
+				OpeningBalance: 10000,
+				HorizonMonths:  tt.horizon,
+			})
+			if comparison.ProjectionMethod != ForecastLinear {
+				t.Fatalf("projection method = %q, want %q", comparison.ProjectionMethod, ForecastLinear)
+			}
+			if math.Abs(comparison.ClosingBalanceDelta-tt.want) > 1e-6 {
+				t.Errorf("closing balance delta = %v, want %v", comparison.ClosingBalanceDelta, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareScenarioWithoutAdjustments(t *testing.T) {
+	comparison := CompareScenario(scenarioHistory(), nil, ScenarioOptions{OpeningBalance: 10000, HorizonMonths: 12})
+
+	// A flat 4000 a month for two years.
+	if math.Abs(comparison.Baseline.ClosingBalance-106000) > 1e-6 {
+		t.Errorf("baseline closing balance = %v, want 106000", comparison.Baseline.ClosingBalance)
+	}
+	if comparison.ClosingBalanceDelta != 0 {
+		t.Errorf("closing balance delta = %v, want 0", comparison.ClosingBalanceDelta)
+	}
+	if comparison.ProjectedFrom != "2025-01-01" {
+		t.Errorf("projected from = %q, want 2025-01-01", comparison.ProjectedFrom)
+	}
+}
+
+func TestCompareScenarioShortHistory(t *testing.T) {
+	series := []TimeSeriesDataPoint{
+		{Date: date(2024, time.January, 5), Value: -1000},
+		{Date: date(2024, time.February, 5), Value: -1000},
+	}
+	adjustments := []ScenarioAdjustment{
+		{Type: AdjustmentOneOff, Amount: -500, Date: datePtr(2024, time.January, 20)},
+		{Type: AdjustmentOneOff, Amount: -9000, Date: datePtr(2024, time.October, 1)},
+		{Type: AdjustmentRecurring, Amount: -100, Start: datePtr(2024, time.January, 25)},
+	}
+	comparison := CompareScenario(series, adjustments, ScenarioOptions{OpeningBalance: 5000, HorizonMonths: 12})
+
+	if comparison.ProjectionMethod != "" {
+		t.Fatalf("projection method = %q, want none for two months", comparison.ProjectionMethod)
+	}
+	baseline, scenario := comparison.Baseline.BalanceSeries, comparison.Scenario.BalanceSeries
+	if last := scenario[len(scenario)-1].Date; !last.Equal(baseline[len(baseline)-1].Date) {
+		t.Errorf("scenario ends on %v, want the baseline end %v", last, baseline[len(baseline)-1].Date)
+	}
+	// Only the January one-off and the January recurring payment fall
+	// inside the history.
+	if comparison.ClosingBalanceDelta != -600 {
+		t.Errorf("closing balance delta = %v, want -600", comparison.ClosingBalanceDelta)
+	}
+	if comparison.Scenario.ZeroCashDate != "" {
+		t.Errorf("zero cash date = %q, want none", comparison.Scenario.ZeroCashDate)
+	}
+}
+
+func TestScenarioAdjustmentValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		adjustment ScenarioAdjustment
+		code       string
+	}{
+		{"one-off", ScenarioAdjustment{Type: AdjustmentOneOff, Amount: 10, Date: datePtr(2025, time.January, 1)}, ""},
+		{"one-off without date", ScenarioAdjustment{Type: AdjustmentOneOff, Amount: 10}, i18n.CodeAdjustmentDateRequired},
+		{"one-off without amount", ScenarioAdjustment{Type: AdjustmentOneOff, Date: datePtr(2025, time.January, 1)}, i18n.CodeAdjustmentAmountRequired},
+		{"recurring without start", ScenarioAdjustment{Type: AdjustmentRecurring, Amount: -10}, i18n.CodeAdjustmentStartRequired},
+		{"recurring ending before it starts", ScenarioAdjustment{Type: AdjustmentRecurring, Amount: -10, Start: datePtr(2025, time.March, 1), End: datePtr(2025, time.February, 1)}, i18n.CodeAdjustmentInvalidPeriod},
+		{"category without name", ScenarioAdjustment{Type: AdjustmentCategoryPercentage, Category: " ", Percent: 10}, i18n.CodeAdjustmentCategoryRequired},
+		{"category cut past zero", ScenarioAdjustment{Type: AdjustmentCategoryPercentage, Category: "Rent", Percent: -101}, i18n.CodeAdjustmentInvalidPercent},
+		{"unknown type", ScenarioAdjustment{Type: "loan"}, i18n.CodeAdjustmentInvalidType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.adjustment.Validate(0)
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			var coded *i18n.Error
+			if !errors.As(err, &coded) || coded.Code != tt.code {
+				t.Fatalf("Validate() = %v, want code %s", err, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package analysis
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+)
+
+// AnalysisMonteCarlo is the analysis type that adds a runway simulation.
+const AnalysisMonteCarlo = "monte_carlo"
+
+const (
+	DefaultSimulations  = 1000
+	MaxSimulations      = 10000
+	DefaultBlockMonths  = 3
+	MaxBlockMonths      = 12
+	SimulationHorizon   = 120
+	minSimulationMonths = 3
+)
+
+var DefaultSurvivalMonths = []int{6, 12, 18, 24}
+
+// runwayPercentiles are the quantiles of the runway reported as dates.
+var runwayPercentiles = []int{10, 50, 90}
+
+// SimulationOptions configure SimulateRunway. Zero values take the defaults;
+// a zero Seed draws a random one, reported back to reproduce the run.
+type SimulationOptions struct {
+	Simulations    int
+	BlockMonths    int
+	SurvivalMonths []int
+	Seed           uint64
+}
+
+// RunwayQuantile is the runway that Percentile percent of the simulations do
+// not exceed, and the date the cash runs out at it. Months and Date are
+// empty when those simulations last beyond the horizon.
+type RunwayQuantile struct {
+	Percentile int      `json:"percentile"`
+	Months     *float64 `json:"months"`
+	Date       string   `json:"date,omitempty"`
+}
+
+// RunwayBucket is the share of simulations whose cash runs out during the
+// Month-th month after the last observed date, counting from zero.
+type RunwayBucket struct {
+	Month       int     `json:"month"`
+	Probability float64 `json:"probability"`
+}
+
+// SurvivalProbability is the share of simulations with cash left after
+// Months months.
+type SurvivalProbability struct {
+	Months      int     `json:"months"`
+	Probability float64 `json:"probability"`
+}
+
+// RunwaySimulation is the outcome of SimulateRunway. HistoryMonths counts the
+// months the blocks are drawn from. Distribution only lists months in which
+// some simulation ran out of cash; BeyondHorizon is the share that never did
+// within HorizonMonths.
+type RunwaySimulation struct {
+	StatusCode     string                `json:"status_code,omitempty"`
+	Simulations    int                   `json:"simulations"`
+	BlockMonths    int                   `json:"block_months"`
+	HorizonMonths  int                   `json:"horizon_months"`
+	Seed           uint64                `json:"seed"`
+	HistoryMonths  int                   `json:"history_months"`
+	CurrentBalance float64               `json:"current_balance"`
+	Quantiles      []RunwayQuantile      `json:"quantiles"`
+	Distribution   []RunwayBucket        `json:"distribution"`
+	BeyondHorizon  float64               `json:"beyond_horizon"`
+	Survival       []SurvivalProbability `json:"survival"`
+}
+
+// SimulateRunway replays the monthly net flows of a series sorted by date in
+// random order to estimate the distribution of the runway. It resamples
+// blocks of consecutive months, a moving block bootstrap, so that runs of
+// good or bad months are kept together. Each simulation starts from the
+// balance at the end of the series and stops when the cash runs out or after
+// SimulationHorizon months.
+func SimulateRunway(series []TimeSeriesDataPoint, openingBalance float64, options SimulationOptions) *RunwaySimulation {
+	if options.Simulations <= 0 {
+		options.Simulations = DefaultSimulations
+	}
+	if options.BlockMonths <= 0 {
+		options.BlockMonths = DefaultBlockMonths
+	}
+	if len(options.SurvivalMonths) == 0 {
+		options.SurvivalMonths = DefaultSurvivalMonths
+	}
+	if options.Seed == 0 {
+		// Kept within 53 bits so JavaScript clients can send it back intact.
+		options.Seed = rand.Uint64() >> 11
+	}
+
+	history := monthlyFlows(series, openingBalance)
+	simulation := &RunwaySimulation{
+		Simulations:   options.Simulations,
+		BlockMonths:   options.BlockMonths,
+		HorizonMonths: SimulationHorizon,
+		Seed:          options.Seed,
+		HistoryMonths: completeMonths(series),
+	}
+	if simulation.HistoryMonths < minSimulationMonths {
+		simulation.StatusCode = HealthInsufficientData
+		return simulation
+	}
+	simulation.CurrentBalance = history[len(history)-1].Balance
+
+	// A last month the data stops part way through is not drawn from.
+	flows := make([]float64, simulation.HistoryMonths)
+	for i := range flows {
+		flows[i] = history[i].NetFlow
+	}
+	blockMonths := min(options.BlockMonths, len(flows))
+
+	random := rand.New(rand.NewPCG(options.Seed, options.Seed))
+	runways := make([]float64, options.Simulations)
+	for i := range runways {
+		runways[i] = simulateRunway(flows, simulation.CurrentBalance, blockMonths, random)
+	}
+	sort.Float64s(runways)
+
+	lastDate := series[len(series)-1].Date
+	for _, percentile := range runwayPercentiles {
+		simulation.Quantiles = append(simulation.Quantiles, runwayQuantile(runways, percentile, lastDate))
+	}
+
+	total := float64(len(runways))
+	simulation.Distribution = []RunwayBucket{}
+	var beyond int
+	for _, runway := range runways {
+		if math.IsInf(runway, 1) {
+			beyond++
+			continue
+		}
+		month := int(runway)
+		last := len(simulation.Distribution) - 1
+		if last == -1 || simulation.Distribution[last].Month != month {
+			simulation.Distribution = append(simulation.Distribution, RunwayBucket{Month: month})
+			last++
+		}
+		// Counted here, turned into a share below.
+		simulation.Distribution[last].Probability++
+	}
+	for i := range simulation.Distribution {
+		simulation.Distribution[i].Probability /= total
+	}
+	simulation.BeyondHorizon = float64(beyond) / total
+
+	for _, months := range options.SurvivalMonths {
+		// Runways are sorted, so the survivors are the ones after the cut.
+		survivors := len(runways) - sort.Search(len(runways), func(i int) bool {
+			return runways[i] > float64(months)
+		})
+		simulation.Survival = append(simulation.Survival, SurvivalProbability{
+			Months:      months,
+			Probability: float64(survivors) / total,
+		})
+	}
+
+	return simulation
+}
+
+// simulateRunway draws blocks of consecutive flows until the balance reaches
+// zero and returns the fractional number of months that took, or +Inf when
+// the cash lasts the whole horizon.
+func simulateRunway(flows []float64, balance float64, blockMonths int, random *rand.Rand) float64 {
+	if balance <= 0 {
+		return 0
+	}
+	for month := 0; month < SimulationHorizon; {
+		start := random.IntN(len(flows) - blockMonths + 1)
+		for _, flow := range flows[start : start+blockMonths] {
+			if month == SimulationHorizon {
+				break
+			}
+			if balance+flow <= 0 {
+				return float64(month) + balance/-flow
+			}
+			balance += flow
+			month++
+		}
+	}
+	return math.Inf(1)
+}
+
+// runwayQuantile picks the nearest rank percentile of sorted runways.
+func runwayQuantile(runways []float64, percentile int, lastDate time.Time) RunwayQuantile {
+	rank := int(math.Ceil(float64(percentile)/100*float64(len(runways)))) - 1
+	quantile := RunwayQuantile{Percentile: percentile}
+	if runway := runways[max(rank, 0)]; !math.IsInf(runway, 1) {
+		quantile.Months = &runway
+		quantile.Date = addMonths(lastDate, runway).Format("02/01/2006")
+	}
+	return quantile
+}
@@ -0,0 +1,133 @@
+package analysis
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+var simulationFlows = []float64{-3000, 1000, -2500, 500, -4000, 2000, -1500, -500, -3500, 1500, -2000, 0}
+
+func TestSimulateRunwaySeeded(t *testing.T) {
+	options := SimulationOptions{Simulations: 200, Seed: 42, SurvivalMonths: []int{6, 12}}
+	simulation := SimulateRunway(monthlySeries(simulationFlows), 20000, options)
+
+	if again := SimulateRunway(monthlySeries(simulationFlows), 20000, options); !reflect.DeepEqual(simulation, again) {
+		t.Fatalf("the same seed gave different runs:\n%+v\n%+v", simulation, again)
+	}
+
+	if simulation.Seed != 42 || simulation.HistoryMonths != 12 || simulation.CurrentBalance != 8000 {
+		t.Errorf("simulation = %+v, want seed 42, 12 months and a balance of 8000", simulation)
+	}
+	// Pinned from a run with seed 42; a change means the sampling changed.
+	wantQuantiles := []struct {
+		months float64
+		date   string
+	}{
+		{4, "10/04/2022"},
+		{7.428571428571429, "23/07/2022"},
+		{12.857142857142858, "05/01/2023"},
+	}
+	for i, want := range wantQuantiles {
+		got := simulation.Quantiles[i]
+		if got.Months == nil || math.Abs(*got.Months-want.months) > 1e-9 || got.Date != want.date {
+			t.Errorf("quantile P%d = %v %s, want %v %s", got.Percentile, got.Months, got.Date, want.months, want.date)
+		}
+	}
+	wantSurvival := []SurvivalProbability{{Months: 6, Probability: 0.675}, {Months: 12, Probability: 0.125}}
+	if !reflect.DeepEqual(simulation.Survival, wantSurvival) {
+		t.Errorf("survival = %+v, want %+v", simulation.Survival, wantSurvival)
+	}
+
+	total := simulation.BeyondHorizon
+	for _, bucket := range simulation.Distribution {
+		total += bucket.Probability
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Errorf("distribution adds up to %v, want 1", total)
+	}
+}
+
+func TestSimulateRunwayDifferentSeeds(t *testing.T) {
+	a := SimulateRunway(monthlySeries(simulationFlows), 20000, SimulationOptions{Simulations: 200, Seed: 1})
+	b := SimulateRunway(monthlySeries(simulationFlows), 20000, SimulationOptions{Simulations: 200, Seed: 2})
+	if reflect.DeepEqual(a.Distribution, b.Distribution) {
+		t.Errorf("seeds 1 and 2 gave the same distribution")
+	}
+
+	random := SimulateRunway(monthlySeries(simulationFlows), 20000, SimulationOptions{})
+	if random.Seed == 0 || random.Seed >= 1<<53 {
+		t.Errorf("drawn seed = %d, want a non-zero seed within 53 bits", random.Seed)
+	}
+	if random.Simulations != DefaultSimulations || random.BlockMonths != DefaultBlockMonths {
+		t.Errorf("defaults = %d simulations of %d month blocks", random.Simulations, random.BlockMonths)
+	}
+}
+
+func TestSimulateRunwayConstantBurn(t *testing.T) {
+	flows := []float64{-1000, -1000, -1000}
+	simulation := SimulateRunway(monthlySeries(flows), 12500, SimulationOptions{Simulations: 50, Seed: 7})
+
+	// 9500 left, burning 1000 a month, whatever the order.
+	for _, q := range simulation.Quantiles {
+		if q.Months == nil || math.Abs(*q.Months-9.5) > 1e-9 {
+			t.Errorf("quantile P%d = %v, want 9.5", q.Percentile, q.Months)
+		}
+	}
+	want := []RunwayBucket{{Month: 9, Probability: 1}}
+	if !reflect.DeepEqual(simulation.Distribution, want) {
+		t.Errorf("distribution = %+v, want %+v", simulation.Distribution, want)
+	}
+}
+
+func TestSimulateRunwayNeverRunsOut(t *testing.T) {
+	flows := []float64{1000, -500, 2000}
+	simulation := SimulateRunway(monthlySeries(flows), 100, SimulationOptions{Simulations: 50, Seed: 7})
+
+	if simulation.BeyondHorizon != 1 || len(simulation.Distribution) != 0 {
+		t.Errorf("beyond horizon = %v with distribution %+v, want every run beyond it", simulation.BeyondHorizon, simulation.Distribution)
+	}
+	for _, q := range simulation.Quantiles {
+		if q.Months != nil || q.Date != "" {
+			t.Errorf("quantile P%d = %v %q, want none", q.Percentile, q.Months, q.Date)
+		}
+	}
+}
+
+func TestSimulateRunwayNoCashLeft(t *testing.T) {
+	for _, balance := range []float64{0, -5000} {
+		// The flows end the history at the given balance.
+		simulation := SimulateRunway(monthlySeries([]float64{-1000, 500, 500}), balance, SimulationOptions{Simulations: 20, Seed: 3})
+
+		if simulation.CurrentBalance != balance {
+			t.Fatalf("current balance = %v, want %v", simulation.CurrentBalance, balance)
+		}
+		for _, q := range simulation.Quantiles {
+			if q.Months == nil || *q.Months != 0 {
+				t.Errorf("balance %v: quantile P%d = %v, want 0", balance, q.Percentile, q.Months)
+			}
+		}
+		if want := []RunwayBucket{{Month: 0, Probability: 1}}; !reflect.DeepEqual(simulation.Distribution, want) {
+			t.Errorf("balance %v: distribution = %+v, want %+v", balance, simulation.Distribution, want)
+		}
+		for _, s := range simulation.Survival {
+			if s.Probability != 0 {
+				t.Errorf("balance %v: survival after %d months = %v, want 0", balance, s.Months, s.Probability)
+			}
+		}
+	}
+}
+
+func TestSimulateRunwayInsufficientData(t *testing.T) {
+	simulation := SimulateRunway(monthlySeries([]float64{-1000, -1000}), 5000, SimulationOptions{Seed: 9})
+
+	if simulation.StatusCode != HealthInsufficientData {
+		t.Errorf("status code = %q, want %q", simulation.StatusCode, HealthInsufficientData)
+	}
+	if simulation.HistoryMonths != 2 || simulation.Quantiles != nil || simulation.Distribution != nil {
+		t.Errorf("simulation = %+v, want no results for two months", simulation)
+	}
+	if simulation.Seed != 9 {
+		t.Errorf("seed = %d, want 9", simulation.Seed)
+	}
+}
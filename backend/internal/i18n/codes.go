@@ -0,0 +1,109 @@
+package i18n
+
+// Error codes. They are part of the API and must not change once released.
+const (
+	CodeInternalError = "internal_error"
+	CodeUnauthorized  = "unauthorized"
+	CodeInvalidBody   = "invalid_body"
+	CodeInvalidID     = "invalid_id"
+	CodeInvalidQuery  = "invalid_query"
+
+	CodeInvalidCredentials  = "invalid_credentials"
+	CodePasswordHashFailed  = "password_hash_failed"
+	CodeTokenCreationFailed = "token_creation_failed"
+	CodeUserCreationFailed  = "user_creation_failed"
+
+	CodeProjectNotFound       = "project_not_found"
+	CodeProjectNameRequired   = "project_name_required"
+	CodeProjectListFailed     = "project_list_failed"
+	CodeFileRequired          = "file_required"
+	CodeFileSaveFailed        = "file_save_failed"
+	CodeFileOpenFailed        = "file_open_failed"
+	CodeInvalidFile           = "invalid_file"
+	CodeEmptyFile             = "empty_file"
+	CodeLegacyXLS             = "legacy_xls_unsupported"
+	CodeUnsupportedFileFormat = "unsupported_file_format"
+	CodeOFXMissingRoot        = "ofx_missing_root"
+	CodeOFXUnclosedTrn        = "ofx_unclosed_transaction"
+	CodeFileTypeMismatch      = "file_type_mismatch"
+	CodeSheetNotFound         = "sheet_not_found"
+
+	CodeProjectNotConfigured      = "project_not_configured"
+	CodeColumnRequired            = "column_required"
+	CodeSplitColumnsRequired      = "split_columns_required"
+	CodeValueColumnNotFound       = "value_column_not_found"
+	CodeInflowColumnNotFound      = "inflow_column_not_found"
+	CodeOutflowColumnNotFound     = "outflow_column_not_found"
+	CodeCategoryColumnNotFound    = "category_column_not_found"
+	CodeDescriptionColumnNotFound = "description_column_not_found"
+	CodeUnsupportedNumberLocale   = "unsupported_number_locale"
+	CodeUnsupportedDateFormat     = "unsupported_date_format"
+	CodeInvalidOpeningDate        = "invalid_opening_balance_date"
+	CodeInvalidOpeningCell        = "invalid_opening_balance_cell"
+	CodeOpeningCellNotNumber      = "opening_balance_cell_not_number"
+
+	CodeInvalidDate        = "invalid_date"
+	CodeInvalidFromDate    = "invalid_from_date"
+	CodeInvalidToDate      = "invalid_to_date"
+	CodeInvalidDateRange   = "invalid_date_range"
+	CodeInvalidRangePreset = "invalid_range_preset"
+	CodeInvalidGranularity = "invalid_granularity"
+	CodeInvalidBurnWindow  = "invalid_burn_window"
+
+	CodeInvalidForecastHorizon   = "invalid_forecast_horizon"
+	CodeInvalidForecastMethod    = "invalid_forecast_method"
+	CodeForecastRequiresDates    = "forecast_requires_dates"
+	CodeForecastInsufficientData = "forecast_insufficient_data"
+
+	CodeInvalidSimulations    = "invalid_simulations"
+	CodeInvalidBlockMonths    = "invalid_block_months"
+	CodeInvalidSurvivalMonths = "invalid_survival_months"
+	CodeAnalysisRequiresDates = "analysis_requires_dates"
+
+	CodeInvalidAnomalyWindow    = "invalid_anomaly_window"
+	CodeInvalidAnomalyThreshold = "invalid_anomaly_threshold"
+
+	CodeScenarioNotFound           = "scenario_not_found"
+	CodeScenarioNameRequired       = "scenario_name_required"
+	CodeInvalidScenarioHorizon     = "invalid_scenario_horizon"
+	CodeScenarioRequiresDates      = "scenario_requires_dates"
+	CodeAdjustmentInvalidType      = "adjustment_invalid_type"
+	CodeAdjustmentInvalidDate      = "adjustment_invalid_date"
+	CodeAdjustmentDateRequired     = "adjustment_date_required"
+	CodeAdjustmentStartRequired    = "adjustment_start_required"
+	CodeAdjustmentAmountRequired   = "adjustment_amount_required"
+	CodeAdjustmentCategoryRequired = "adjustment_category_required"
+	CodeAdjustmentInvalidPercent   = "adjustment_invalid_percent"
+	CodeAdjustmentInvalidPeriod    = "adjustment_invalid_period"
+
+	CodeInvalidBudgetMonth         = "invalid_budget_month"
+	CodeBudgetLineNotFound         = "budget_line_not_found"
+	CodeBudgetMonthColumnNotFound  = "budget_month_column_not_found"
+	CodeBudgetAmountColumnNotFound = "budget_amount_column_not_found"
+
+	CodeTransactionNotFound = "transaction_not_found"
+
+	CodeHealthPolicyInvalidBand   = "health_policy_invalid_band"
+	CodeHealthPolicyMissingCode   = "health_policy_missing_code"
+	CodeHealthPolicyMissingStatus = "health_policy_missing_status"
+)
+
+// Success message codes.
+const (
+	CodeProjectCreated      = "project_created"
+	CodeProjectDeleted      = "project_deleted"
+	CodeFileUpdated         = "file_updated"
+	CodeSettingsSaved       = "settings_saved"
+	CodeTransactionCreated  = "transaction_created"
+	CodeTransactionUpdated  = "transaction_updated"
+	CodeTransactionDeleted  = "transaction_deleted"
+	CodeHealthPolicySaved   = "health_policy_saved"
+	CodeHealthPolicyDeleted = "health_policy_deleted"
+	CodeScenarioCreated     = "scenario_created"
+	CodeScenarioUpdated     = "scenario_updated"
+	CodeScenarioDeleted     = "scenario_deleted"
+	CodeBudgetSaved         = "budget_saved"
+	CodeBudgetImported      = "budget_imported"
+	CodeBudgetLineDeleted   = "budget_line_deleted"
+	CodeLoggedOut           = "logged_out"
+)
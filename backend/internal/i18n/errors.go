@@ -0,0 +1,32 @@
+package i18n
+
+import (
+	"errors"
+	"slices"
+)
+
+// Error is an error identified by a catalog code. Its Error text is the
+// message in the default language, for logs and stored diagnostics.
+type Error struct {
+	Code   string
+	Params Params
+}
+
+func NewError(code string, params Params) *Error {
+	return &Error{Code: code, Params: params}
+}
+
+func (e *Error) Error() string {
+	return T(DefaultLanguage, e.Code, e.Params)
+}
+
+// Message returns the error message in the given language.
+func (e *Error) Message(language string) string {
+	return T(language, e.Code, e.Params)
+}
+
+// HasCode reports whether err is an Error with one of the given codes.
+func HasCode(err error, codes ...string) bool {
+	var coded *Error
+	return errors.As(err, &coded) && slices.Contains(codes, coded.Code)
+}
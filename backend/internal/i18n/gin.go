@@ -0,0 +1,48 @@
+package i18n
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const languageKey = "language"
+
+// Middleware negotiates the response language from Accept-Language.
+func Middleware(c *gin.Context) {
+	language := Negotiate(c.GetHeader("Accept-Language"))
+	c.Set(languageKey, language)
+	c.Header("Content-Language", language)
+	c.Next()
+}
+
+func Language(c *gin.Context) string {
+	if language, ok := c.Get(languageKey); ok {
+		return language.(string)
+	}
+	return Negotiate(c.GetHeader("Accept-Language"))
+}
+
+// RespondError writes {"code", "error"} for err. Errors without a code are
+// reported as internal errors with their text as detail.
+func RespondError(c *gin.Context, status int, err error) {
+	var coded *Error
+	if !errors.As(err, &coded) {
+		coded = NewError(CodeInternalError, Params{"detail": err.Error()})
+	}
+	c.JSON(status, gin.H{
+		"code":  coded.Code,
+		"error": coded.Message(Language(c)),
+	})
+}
+
+// RespondCode writes the error identified by code.
+func RespondCode(c *gin.Context, status int, code string, params Params) {
+	RespondError(c, status, NewError(code, params))
+}
+
+// Message returns the localized success message for code, to be sent along
+// with the code itself.
+func Message(c *gin.Context, code string) string {
+	return T(Language(c), code, nil)
+}
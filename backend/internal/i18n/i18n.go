@@ -0,0 +1,104 @@
+// Package i18n holds the message catalogs behind the codes returned by the
+// API. Every error and status carries a stable code; the human message is
+// picked from the catalog of the language the client asked for.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const DefaultLanguage = "pt-BR"
+
+// Languages lists the catalogs available, the default first.
+var Languages = []string{DefaultLanguage, "en"}
+
+//go:embed catalogs/*.json
+var catalogFiles embed.FS
+
+var catalogs = map[string]map[string]string{}
+
+// Params fill the {name} placeholders of a message.
+type Params map[string]string
+
+func init() {
+	for _, language := range Languages {
+		data, err := catalogFiles.ReadFile(path.Join("catalogs", language+".json"))
+		if err != nil {
+			panic(err)
+		}
+		catalog := map[string]string{}
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic("i18n: " + language + ": " + err.Error())
+		}
+		catalogs[language] = catalog
+	}
+}
+
+// Lookup returns the message for code in the given language, falling back to
+// the default language.
+func Lookup(language, code string, params Params) (string, bool) {
+	message, ok := catalogs[language][code]
+	if !ok {
+		message, ok = catalogs[DefaultLanguage][code]
+	}
+	if !ok {
+		return "", false
+	}
+	for name, value := range params {
+		message = strings.ReplaceAll(message, "{"+name+"}", value)
+	}
+	return message, true
+}
+
+// T returns the message for code, or the code itself when no catalog has it.
+func T(language, code string, params Params) string {
+	if message, ok := Lookup(language, code, params); ok {
+		return message
+	}
+	return code
+}
+
+// Negotiate picks the catalog that best matches an Accept-Language header.
+// Only the primary subtag is compared, so "pt-PT" gets pt-BR and "en-GB"
+// gets en.
+func Negotiate(acceptLanguage string) string {
+	best, bestQuality := DefaultLanguage, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, quality := parseLanguageRange(part)
+		if quality <= bestQuality {
+			continue
+		}
+		if language, ok := matchLanguage(tag); ok {
+			best, bestQuality = language, quality
+		}
+	}
+	return best
+}
+
+func parseLanguageRange(part string) (string, float64) {
+	tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+	quality := 1.0
+	for _, param := range strings.Split(params, ";") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+			if q, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = q
+			}
+		}
+	}
+	return strings.TrimSpace(tag), quality
+}
+
+func matchLanguage(tag string) (string, bool) {
+	primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+	for _, language := range Languages {
+		languagePrimary, _, _ := strings.Cut(strings.ToLower(language), "-")
+		if primary == languagePrimary {
+			return language, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"finview/backend/internal/i18n"
+	"finview/backend/internal/projects/service"
+	userModel "finview/backend/internal/user/model"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type budgetLineInput struct {
+	Month    string   `json:"month" binding:"required"`
+	Category string   `json:"category"`
+	Amount   *float64 `json:"amount" binding:"required"`
+}
+
+type budgetInput struct {
+	Lines []budgetLineInput `json:"lines" binding:"required,dive"`
+}
+
+type budgetImportInput struct {
+	Sheet          string `json:"sheet"`
+	Line           int    `json:"line" binding:"required"`
+	MonthColumn    string `json:"month_column" binding:"required"`
+	CategoryColumn string `json:"category_column"`
+	AmountColumn   string `json:"amount_column" binding:"required"`
+}
+
+func ListBudget(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+
+	lines, err := service.ListBudget(user.ID, uint(projectID))
+	if err != nil {
+		i18n.RespondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lines": lines})
+}
+
+func SaveBudgetLines(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+
+	var input budgetInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidBody, i18n.Params{"detail": err.Error()})
+		return
+	}
+
+	lines := make([]service.BudgetLineInput, len(input.Lines))
+	for i, line := range input.Lines {
+		lines[i] = service.BudgetLineInput{Month: line.Month, Category: line.Category, Amount: *line.Amount}
+	}
+
+	saved, err := service.SaveBudgetLines(user.ID, uint(projectID), lines)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    i18n.CodeBudgetSaved,
+		"message": i18n.Message(c, i18n.CodeBudgetSaved),
+		"lines":   saved,
+	})
+}
+
+func ImportBudget(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+
+	var input budgetImportInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidBody, i18n.Params{"detail": err.Error()})
+		return
+	}
+
+	imported, err := service.ImportBudget(user.ID, uint(projectID), service.BudgetImportSettings{
+		Sheet:          input.Sheet,
+		Line:           input.Line,
+		MonthColumn:    input.MonthColumn,
+		CategoryColumn: input.CategoryColumn,
+		AmountColumn:   input.AmountColumn,
+	})
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":        i18n.CodeBudgetImported,
+		"message":     i18n.Message(c, i18n.CodeBudgetImported),
+		"lines":       imported.Lines,
+		"diagnostics": imported.Diagnostics,
+	})
+}
+
+func DeleteBudgetLine(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+	lineID, err := strconv.ParseUint(c.Param("lineId"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+
+	if err := service.DeleteBudgetLine(user.ID, uint(projectID), uint(lineID)); err != nil {
+		i18n.RespondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    i18n.CodeBudgetLineDeleted,
+		"message": i18n.Message(c, i18n.CodeBudgetLineDeleted),
+	})
+}
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"finview/backend/internal/analysis"
+	"finview/backend/internal/i18n"
+	"finview/backend/internal/projects/service"
+	userModel "finview/backend/internal/user/model"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetUserHealthPolicy(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	policy, err := service.GetUserHealthPolicy(user.ID)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func UpdateUserHealthPolicy(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	rules, ok := bindHealthPolicy(c)
+	if !ok {
+		return
+	}
+
+	policy, err := service.SaveUserHealthPolicy(user.ID, rules)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    i18n.CodeHealthPolicySaved,
+		"message": i18n.Message(c, i18n.CodeHealthPolicySaved),
+		"policy":  policy,
+	})
+}
+
+func DeleteUserHealthPolicy(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	if err := service.DeleteUserHealthPolicy(user.ID); err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    i18n.CodeHealthPolicyDeleted,
+		"message": i18n.Message(c, i18n.CodeHealthPolicyDeleted),
+	})
+}
+
+func GetProjectHealthPolicy(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+
+	policy, err := service.GetProjectHealthPolicy(user.ID, uint(projectID))
+	if err != nil {
+		i18n.RespondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func UpdateProjectHealthPolicy(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+
+	rules, ok := bindHealthPolicy(c)
+	if !ok {
+		return
+	}
+
+	policy, err := service.SaveProjectHealthPolicy(user.ID, uint(projectID), rules)
+	if err != nil {
+		i18n.RespondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    i18n.CodeHealthPolicySaved,
+		"message": i18n.Message(c, i18n.CodeHealthPolicySaved),
+		"policy":  policy,
+	})
+}
+
+func DeleteProjectHealthPolicy(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+
+	if err := service.DeleteProjectHealthPolicy(user.ID, uint(projectID)); err != nil {
+		i18n.RespondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    i18n.CodeHealthPolicyDeleted,
+		"message": i18n.Message(c, i18n.CodeHealthPolicyDeleted),
+	})
+}
+
+func bindHealthPolicy(c *gin.Context) (analysis.HealthPolicy, bool) {
+	var rules analysis.HealthPolicy
+	if err := c.ShouldBindJSON(&rules); err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidBody, i18n.Params{"detail": err.Error()})
+		return rules, false
+	}
+	if err := rules.Validate(); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err)
+		return rules, false
+	}
+	return rules, true
+}
@@ -1,10 +1,16 @@
 package controller
 
 import (
+	"finview/backend/internal/analysis"
+	"finview/backend/internal/i18n"
+	projectModel "finview/backend/internal/projects/model"
 	"finview/backend/internal/projects/service"
 	userModel "finview/backend/internal/user/model"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,32 +19,34 @@ import (
 func UploadProject(c *gin.Context) {
 	userInterface, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticate"})
+		i18n.RespondCode(c, http.StatusUnauthorized, i18n.CodeUnauthorized, nil)
 		return
 	}
 	user := userInterface.(userModel.User)
 
 	projectName := c.PostForm("name")
 	if projectName == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Project name required"})
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeProjectNameRequired, nil)
 		return
 	}
 
 	file, err := c.FormFile("project_file")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Files not fount"})
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeFileRequired, nil)
 		return
 	}
 
-	project, err := service.CreateProject(file, user.ID, projectName)
+	project, detected, err := service.CreateProject(file, user.ID, projectName, c.PostForm("source_type"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process files"})
+		i18n.RespondError(c, http.StatusInternalServerError, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Projeto criado com sucesso!",
-		"project": project,
+		"code":      i18n.CodeProjectCreated,
+		"message":   i18n.Message(c, i18n.CodeProjectCreated),
+		"project":   project,
+		"detection": detected,
 	})
 }
 
@@ -48,7 +56,7 @@ func GetUserProjects(c *gin.Context) {
 
 	projects, err := service.GetProjectsForUser(user.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get projects"})
+		i18n.RespondCode(c, http.StatusInternalServerError, i18n.CodeProjectListFailed, nil)
 		return
 	}
 
@@ -56,16 +64,27 @@ func GetUserProjects(c *gin.Context) {
 }
 
 type settingsInput struct {
-	Sheet      string `json:"sheet" binding:"required"`
-	Column     string `json:"column" binding:"required"`
-	DateColumn string `json:"date_column"`
-	Line       int    `json:"line" binding:"required"`
+	Sheet             string `json:"sheet" binding:"required"`
+	Column            string `json:"column"`
+	DateColumn        string `json:"date_column"`
+	CategoryColumn    string `json:"category_column"`
+	DescriptionColumn string `json:"description_column"`
+	AmountMode        string `json:"amount_mode" binding:"omitempty,oneof=signed split"`
+	InflowColumn      string `json:"inflow_column"`
+	OutflowColumn     string `json:"outflow_column"`
+	NumberLocale      string `json:"number_locale"`
+	DateFormat        string `json:"date_format"`
+	Line              int    `json:"line" binding:"required"`
+
+	OpeningBalance     float64 `json:"opening_balance"`
+	OpeningBalanceDate string  `json:"opening_balance_date"`
+	OpeningBalanceCell string  `json:"opening_balance_cell"`
 }
 
 func UpdateProjectFile(c *gin.Context) {
     userInterface, exists := c.Get("user")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Não autorizado"})
+        i18n.RespondCode(c, http.StatusUnauthorized, i18n.CodeUnauthorized, nil)
         return
     }
     user := userInterface.(userModel.User)
@@ -73,24 +92,25 @@ func UpdateProjectFile(c *gin.Context) {
     idStr := c.Param("id")
     projectID, err := strconv.ParseUint(idStr, 10, 32)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+        i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
         return
     }
 
     file, err := c.FormFile("project_file")
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Arquivo é obrigatório"})
+        i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeFileRequired, nil)
         return
     }
 
-    project, err := service.UpdateProjectFile(user.ID, uint(projectID), file)
+    project, err := service.UpdateProjectFile(user.ID, uint(projectID), file, c.PostForm("source_type"))
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar arquivo: " + err.Error()})
+        i18n.RespondError(c, http.StatusInternalServerError, err)
         return
     }
 
     c.JSON(http.StatusOK, gin.H{
-        "message": "Arquivo atualizado com sucesso!",
+        "code":    i18n.CodeFileUpdated,
+        "message": i18n.Message(c, i18n.CodeFileUpdated),
         "project": project,
     })
 }
@@ -102,24 +122,66 @@ func UpdateProjectSettings(c *gin.Context) {
 	idStr := c.Param("id")
 	projectID, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de projeto inválido"})
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
 		return
 	}
 
 	var input settingsInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidBody, i18n.Params{"detail": err.Error()})
+		return
+	}
+	if input.AmountMode == projectModel.AmountModeSplit {
+		if input.InflowColumn == "" || input.OutflowColumn == "" {
+			i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeSplitColumnsRequired, nil)
+			return
+		}
+	} else if input.Column == "" {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeColumnRequired, nil)
+		return
+	}
+	if input.NumberLocale != "" && !service.IsSupportedNumberLocale(input.NumberLocale) {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeUnsupportedNumberLocale, i18n.Params{"value": input.NumberLocale})
+		return
+	}
+	if !service.IsSupportedDateFormat(input.DateFormat) {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeUnsupportedDateFormat, i18n.Params{"value": input.DateFormat})
+		return
+	}
+	if input.OpeningBalanceDate != "" && !service.IsValidDate(input.OpeningBalanceDate) {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidOpeningDate, i18n.Params{"value": input.OpeningBalanceDate})
+		return
+	}
+	if input.OpeningBalanceCell != "" && !service.IsValidCellReference(input.OpeningBalanceCell) {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidOpeningCell, i18n.Params{"value": input.OpeningBalanceCell})
 		return
 	}
 
-	project, err := service.UpdateProjectSettings(user.ID, uint(projectID), input.Sheet, input.Column, input.DateColumn, input.Line)
+	project, err := service.UpdateProjectSettings(user.ID, uint(projectID), service.ProjectSettings{
+		Sheet:             input.Sheet,
+		Column:            input.Column,
+		DateColumn:        input.DateColumn,
+		CategoryColumn:    input.CategoryColumn,
+		DescriptionColumn: input.DescriptionColumn,
+		AmountMode:        input.AmountMode,
+		InflowColumn:      input.InflowColumn,
+		OutflowColumn:     input.OutflowColumn,
+		NumberLocale:      input.NumberLocale,
+		DateFormat:        input.DateFormat,
+		Line:              input.Line,
+
+		OpeningBalance:     input.OpeningBalance,
+		OpeningBalanceDate: input.OpeningBalanceDate,
+		OpeningBalanceCell: input.OpeningBalanceCell,
+	})
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()}) 
+		i18n.RespondError(c, http.StatusNotFound, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Configuration saved successfully",
+		"code":    i18n.CodeSettingsSaved,
+		"message": i18n.Message(c, i18n.CodeSettingsSaved),
 		"project": project,
 	})
 }
@@ -133,22 +195,208 @@ func GetProjectAnalysis(c *gin.Context) {
 	idStr := c.Param("id")
 	projectID, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
 		return
 	}
 
 
 	analysisType := c.DefaultQuery("type", "full_analysis")
 
-	result, err := service.GetProjectAnalysis(user.ID, uint(projectID), analysisType)
+	granularity, err := analysis.ParseGranularity(c.Query("granularity"))
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	dateRange, err := service.ResolveDateRange(c.Query("range"), c.Query("from"), c.Query("to"), time.Now())
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	burnWindow := analysis.DefaultBurnWindowMonths
+	if raw := c.Query("burn_window"); raw != "" {
+		burnWindow, err = strconv.Atoi(raw)
+		if err != nil || !slices.Contains(analysis.BurnWindowMonths, burnWindow) {
+			i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidBurnWindow, nil)
+			return
+		}
+	}
+
+	simulation, err := parseSimulationOptions(c)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var anomaly analysis.AnomalyOptions
+	if raw := c.Query("anomaly_window"); raw != "" {
+		anomaly.WindowMonths, err = strconv.Atoi(raw)
+		if err != nil || anomaly.WindowMonths < analysis.MinAnomalyWindow || anomaly.WindowMonths > analysis.MaxAnomalyWindow {
+			i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidAnomalyWindow, i18n.Params{
+				"min": strconv.Itoa(analysis.MinAnomalyWindow),
+				"max": strconv.Itoa(analysis.MaxAnomalyWindow),
+			})
+			return
+		}
+	}
+	if raw := c.Query("anomaly_threshold"); raw != "" {
+		anomaly.Threshold, err = strconv.ParseFloat(raw, 64)
+		if err != nil || anomaly.Threshold <= 0 {
+			i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidAnomalyThreshold, nil)
+			return
+		}
+	}
+
+	result, err := service.GetProjectAnalysis(user.ID, uint(projectID), analysisType, service.AnalysisOptions{
+		Granularity:      granularity,
+		Range:            dateRange,
+		BurnWindowMonths: burnWindow,
+		Language:         i18n.Language(c),
+		Simulation:       simulation,
+		Anomaly:          anomaly,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		status := http.StatusInternalServerError
+		switch {
+		case i18n.HasCode(err, i18n.CodeProjectNotFound):
+			status = http.StatusNotFound
+		case i18n.HasCode(err, i18n.CodeAnalysisRequiresDates),
+			i18n.HasCode(err, service.ImportErrorCodes...):
+			status = http.StatusUnprocessableEntity
+		}
+		i18n.RespondError(c, status, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, result)
 }
 
+// parseSimulationOptions reads the monte_carlo query parameters: simulations,
+// block_months, survival_months as a comma separated list, and seed.
+func parseSimulationOptions(c *gin.Context) (analysis.SimulationOptions, error) {
+	var options analysis.SimulationOptions
+	var err error
+
+	if raw := c.Query("simulations"); raw != "" {
+		options.Simulations, err = strconv.Atoi(raw)
+		if err != nil || options.Simulations < 1 || options.Simulations > analysis.MaxSimulations {
+			return options, i18n.NewError(i18n.CodeInvalidSimulations, i18n.Params{"max": strconv.Itoa(analysis.MaxSimulations)})
+		}
+	}
+	if raw := c.Query("block_months"); raw != "" {
+		options.BlockMonths, err = strconv.Atoi(raw)
+		if err != nil || options.BlockMonths < 1 || options.BlockMonths > analysis.MaxBlockMonths {
+			return options, i18n.NewError(i18n.CodeInvalidBlockMonths, i18n.Params{"max": strconv.Itoa(analysis.MaxBlockMonths)})
+		}
+	}
+	if raw := c.Query("survival_months"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			months, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || months < 1 || months > analysis.SimulationHorizon {
+				return options, i18n.NewError(i18n.CodeInvalidSurvivalMonths, i18n.Params{"max": strconv.Itoa(analysis.SimulationHorizon)})
+			}
+			options.SurvivalMonths = append(options.SurvivalMonths, months)
+		}
+	}
+	if raw := c.Query("seed"); raw != "" {
+		options.Seed, err = strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return options, i18n.NewError(i18n.CodeInvalidQuery, i18n.Params{"param": "seed"})
+		}
+	}
+	return options, nil
+}
+
+func GetProjectForecast(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	idStr := c.Param("id")
+	projectID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+
+	horizon, err := strconv.Atoi(c.DefaultQuery("horizon", strconv.Itoa(analysis.DefaultForecastHorizon)))
+	if err != nil || horizon < 1 || horizon > analysis.MaxForecastHorizon {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidForecastHorizon, i18n.Params{"max": strconv.Itoa(analysis.MaxForecastHorizon)})
+		return
+	}
+
+	method, err := analysis.ParseForecastMethod(c.Query("method"))
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	forecast, err := service.GetProjectForecast(user.ID, uint(projectID), service.ForecastOptions{
+		Horizon: horizon,
+		Method:  method,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case i18n.HasCode(err, i18n.CodeProjectNotFound):
+			status = http.StatusNotFound
+		case i18n.HasCode(err, i18n.CodeForecastRequiresDates, i18n.CodeForecastInsufficientData),
+			i18n.HasCode(err, service.ImportErrorCodes...):
+			status = http.StatusUnprocessableEntity
+		}
+		i18n.RespondError(c, status, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
+
+func GetProjectSchema(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	idStr := c.Param("id")
+	projectID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+
+	schema, err := service.GetProjectSchema(user.ID, uint(projectID))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schema)
+}
+
+func GetProjectPreview(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	idStr := c.Param("id")
+	projectID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidQuery, i18n.Params{"param": "limit"})
+		return
+	}
+
+	preview, err := service.GetProjectPreview(user.ID, uint(projectID), limit)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
 func DeleteProject(c *gin.Context) {
     userInterface, _ := c.Get("user")
     user := userInterface.(userModel.User)
@@ -156,15 +404,18 @@ func DeleteProject(c *gin.Context) {
     idStr := c.Param("id")
     projectID, err := strconv.ParseUint(idStr, 10, 32)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+        i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
         return
     }
 
     err = service.DeleteProject(user.ID, uint(projectID))
     if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Erro ao deletar projeto: " + err.Error()})
+        i18n.RespondError(c, http.StatusNotFound, err)
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"message": "Projeto deletado com sucesso"})
+    c.JSON(http.StatusOK, gin.H{
+        "code":    i18n.CodeProjectDeleted,
+        "message": i18n.Message(c, i18n.CodeProjectDeleted),
+    })
 }
@@ -0,0 +1,235 @@
+package controller
+
+import (
+	"finview/backend/internal/analysis"
+	"finview/backend/internal/i18n"
+	"finview/backend/internal/projects/service"
+	userModel "finview/backend/internal/user/model"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type adjustmentInput struct {
+	Type        string  `json:"type" binding:"required"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+	Date        string  `json:"date"`
+	Start       string  `json:"start"`
+	End         string  `json:"end"`
+	Category    string  `json:"category"`
+	Percent     float64 `json:"percent"`
+}
+
+type scenarioInput struct {
+	Name        string            `json:"name" binding:"required"`
+	Description string            `json:"description"`
+	Adjustments []adjustmentInput `json:"adjustments" binding:"dive"`
+}
+
+func (input scenarioInput) toService() service.ScenarioInput {
+	scenario := service.ScenarioInput{
+		Name:        input.Name,
+		Description: input.Description,
+		Adjustments: make([]service.AdjustmentInput, len(input.Adjustments)),
+	}
+	for i, a := range input.Adjustments {
+		scenario.Adjustments[i] = service.AdjustmentInput{
+			Type:        a.Type,
+			Description: a.Description,
+			Amount:      a.Amount,
+			Date:        a.Date,
+			Start:       a.Start,
+			End:         a.End,
+			Category:    a.Category,
+			Percent:     a.Percent,
+		}
+	}
+	return scenario
+}
+
+func ListScenarios(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+
+	scenarios, err := service.ListScenarios(user.ID, uint(projectID))
+	if err != nil {
+		i18n.RespondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"scenarios": scenarios})
+}
+
+func GetScenario(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	projectID, scenarioID, ok := scenarioIDs(c)
+	if !ok {
+		return
+	}
+
+	scenario, err := service.GetScenario(user.ID, projectID, scenarioID)
+	if err != nil {
+		i18n.RespondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, scenario)
+}
+
+func CreateScenario(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+
+	var input scenarioInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidBody, i18n.Params{"detail": err.Error()})
+		return
+	}
+
+	scenario, err := service.CreateScenario(user.ID, uint(projectID), input.toService())
+	if err != nil {
+		i18n.RespondError(c, scenarioInputErrorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":     i18n.CodeScenarioCreated,
+		"message":  i18n.Message(c, i18n.CodeScenarioCreated),
+		"scenario": scenario,
+	})
+}
+
+func UpdateScenario(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	projectID, scenarioID, ok := scenarioIDs(c)
+	if !ok {
+		return
+	}
+
+	var input scenarioInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidBody, i18n.Params{"detail": err.Error()})
+		return
+	}
+
+	scenario, err := service.UpdateScenario(user.ID, projectID, scenarioID, input.toService())
+	if err != nil {
+		i18n.RespondError(c, scenarioInputErrorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":     i18n.CodeScenarioUpdated,
+		"message":  i18n.Message(c, i18n.CodeScenarioUpdated),
+		"scenario": scenario,
+	})
+}
+
+func DeleteScenario(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	projectID, scenarioID, ok := scenarioIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteScenario(user.ID, projectID, scenarioID); err != nil {
+		i18n.RespondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    i18n.CodeScenarioDeleted,
+		"message": i18n.Message(c, i18n.CodeScenarioDeleted),
+	})
+}
+
+func CompareScenario(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	projectID, scenarioID, ok := scenarioIDs(c)
+	if !ok {
+		return
+	}
+
+	horizon, err := strconv.Atoi(c.DefaultQuery("horizon", strconv.Itoa(analysis.DefaultScenarioHorizon)))
+	if err != nil || horizon < 0 || horizon > analysis.MaxScenarioHorizon {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidScenarioHorizon, i18n.Params{"max": strconv.Itoa(analysis.MaxScenarioHorizon)})
+		return
+	}
+
+	burnWindow := analysis.DefaultBurnWindowMonths
+	if raw := c.Query("burn_window"); raw != "" {
+		burnWindow, err = strconv.Atoi(raw)
+		if err != nil || !slices.Contains(analysis.BurnWindowMonths, burnWindow) {
+			i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidBurnWindow, nil)
+			return
+		}
+	}
+
+	comparison, err := service.CompareScenario(user.ID, projectID, scenarioID, service.ScenarioCompareOptions{
+		HorizonMonths:    horizon,
+		BurnWindowMonths: burnWindow,
+		Language:         i18n.Language(c),
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case i18n.HasCode(err, i18n.CodeProjectNotFound, i18n.CodeScenarioNotFound):
+			status = http.StatusNotFound
+		case i18n.HasCode(err, i18n.CodeScenarioRequiresDates),
+			i18n.HasCode(err, service.ImportErrorCodes...):
+			status = http.StatusUnprocessableEntity
+		}
+		i18n.RespondError(c, status, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
+
+// scenarioInputErrorStatus answers a missing project or scenario with 404 and
+// an invalid scenario with 400.
+func scenarioInputErrorStatus(err error) int {
+	if i18n.HasCode(err, i18n.CodeProjectNotFound, i18n.CodeScenarioNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// scenarioIDs parses the project and scenario IDs of the route, answering
+// the request itself when one is invalid.
+func scenarioIDs(c *gin.Context) (uint, uint, bool) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return 0, 0, false
+	}
+	scenarioID, err := strconv.ParseUint(c.Param("scenarioId"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return 0, 0, false
+	}
+	return uint(projectID), uint(scenarioID), true
+}
@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"finview/backend/internal/i18n"
+	"finview/backend/internal/projects/service"
+	userModel "finview/backend/internal/user/model"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type transactionInput struct {
+	Date        string   `json:"date" binding:"required"`
+	Amount      *float64 `json:"amount" binding:"required"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+}
+
+func (input transactionInput) toService() service.TransactionInput {
+	return service.TransactionInput{
+		Date:        input.Date,
+		Amount:      *input.Amount,
+		Description: input.Description,
+		Category:    input.Category,
+	}
+}
+
+func ListTransactions(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+
+	filter := service.TransactionFilter{
+		From: c.Query("from"),
+		To:   c.Query("to"),
+	}
+	if filter.Page, err = strconv.Atoi(c.DefaultQuery("page", "1")); err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidQuery, i18n.Params{"param": "page"})
+		return
+	}
+	if filter.PageSize, err = strconv.Atoi(c.DefaultQuery("page_size", "0")); err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidQuery, i18n.Params{"param": "page_size"})
+		return
+	}
+	if filter.MinAmount, err = optionalFloatQuery(c, "min_amount"); err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidQuery, i18n.Params{"param": "min_amount"})
+		return
+	}
+	if filter.MaxAmount, err = optionalFloatQuery(c, "max_amount"); err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidQuery, i18n.Params{"param": "max_amount"})
+		return
+	}
+	if manual := c.Query("manual"); manual != "" {
+		value, err := strconv.ParseBool(manual)
+		if err != nil {
+			i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidQuery, i18n.Params{"param": "manual"})
+			return
+		}
+		filter.Manual = &value
+	}
+
+	page, err := service.ListTransactions(user.ID, uint(projectID), filter)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func CreateTransaction(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+
+	var input transactionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidBody, i18n.Params{"detail": err.Error()})
+		return
+	}
+
+	transaction, err := service.CreateManualTransaction(user.ID, uint(projectID), input.toService())
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":        i18n.CodeTransactionCreated,
+		"message":     i18n.Message(c, i18n.CodeTransactionCreated),
+		"transaction": transaction,
+	})
+}
+
+func UpdateTransaction(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+	transactionID, err := strconv.ParseUint(c.Param("transactionId"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+
+	var input transactionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidBody, i18n.Params{"detail": err.Error()})
+		return
+	}
+
+	transaction, err := service.UpdateManualTransaction(user.ID, uint(projectID), uint(transactionID), input.toService())
+	if err != nil {
+		i18n.RespondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":        i18n.CodeTransactionUpdated,
+		"message":     i18n.Message(c, i18n.CodeTransactionUpdated),
+		"transaction": transaction,
+	})
+}
+
+func DeleteTransaction(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	user := userInterface.(userModel.User)
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+	transactionID, err := strconv.ParseUint(c.Param("transactionId"), 10, 32)
+	if err != nil {
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidID, nil)
+		return
+	}
+
+	if err := service.DeleteManualTransaction(user.ID, uint(projectID), uint(transactionID)); err != nil {
+		i18n.RespondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    i18n.CodeTransactionDeleted,
+		"message": i18n.Message(c, i18n.CodeTransactionDeleted),
+	})
+}
+
+func optionalFloatQuery(c *gin.Context, key string) (*float64, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BudgetLine is the amount planned for a category in a calendar month. Month
+// is the first day of the month; there is at most one line per project,
+// month and category. Amounts are signed like the transactions.
+type BudgetLine struct {
+	gorm.Model
+
+	ProjectID uint      `gorm:"not null;uniqueIndex:idx_budget_line"`
+	Month     time.Time `gorm:"not null;uniqueIndex:idx_budget_line"`
+	Category  string    `gorm:"uniqueIndex:idx_budget_line"`
+	Amount    float64
+}
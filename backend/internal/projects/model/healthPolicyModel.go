@@ -0,0 +1,17 @@
+package model
+
+import (
+	"finview/backend/internal/analysis"
+
+	"gorm.io/gorm"
+)
+
+// HealthPolicy stores the health rules of a user, when ProjectID is nil, or of
+// one of their projects. A project policy takes precedence over the user's.
+type HealthPolicy struct {
+	gorm.Model
+
+	UserID    uint                  `gorm:"not null;index"`
+	ProjectID *uint                 `gorm:"index"`
+	Rules     analysis.HealthPolicy `gorm:"serializer:json"`
+}
@@ -1,19 +1,58 @@
 package model
 
 import (
+	"finview/backend/internal/analysis"
+	"finview/backend/internal/i18n"
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// How the amount of a row is read: from one signed column, or split into an
+// inflow and an outflow column.
+const (
+	AmountModeSigned = "signed"
+	AmountModeSplit  = "split"
+)
+
 type Project struct {
 	gorm.Model 
 
 	Name             string `gorm:"not null"`
 	ArqPath          string `gorm:"not null;unique"` 
 	OriginalFilename string
+	SourceType       string
 	ConfigSheet 	 string
 	ConfigColumn     string
 	ConfigDateColumn string
 	ConfigLine       int
 
+	ConfigCategoryColumn    string
+	ConfigDescriptionColumn string
+	ConfigAmountMode        string `gorm:"default:signed"`
+	ConfigInflowColumn      string
+	ConfigOutflowColumn     string
+	ConfigNumberLocale      string
+	ConfigDateFormat        string
+
+	// Bank balance the transactions start from, as of the end of
+	// ConfigOpeningBalanceDate. ConfigOpeningBalanceCell, a cell such as "B2"
+	// or "Resumo!B2", reads the amount from the file instead.
+	ConfigOpeningBalance     float64
+	ConfigOpeningBalanceDate *time.Time
+	ConfigOpeningBalanceCell string
+
+	// Outcome of the last import of the file into the transactions table.
+	ImportedAt    *time.Time
+	ImportDated   bool
+	ImportColumn  string
+	ImportSummary analysis.ParseSummary `gorm:"serializer:json"`
+	ImportError   string
+	// Code and parameters of ImportError, to report it in the client's language.
+	ImportErrorCode   string
+	ImportErrorParams i18n.Params `gorm:"serializer:json"`
+	// Amount read from ConfigOpeningBalanceCell.
+	ImportOpeningBalance *float64
+
 	UserID uint `gorm:"not null"`
 }
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"finview/backend/internal/analysis"
+
+	"gorm.io/gorm"
+)
+
+// Scenario is a what-if variant of a project: adjustments layered onto its
+// cash flow without touching the transactions.
+type Scenario struct {
+	gorm.Model
+
+	ProjectID   uint   `gorm:"not null;index"`
+	Name        string `gorm:"not null"`
+	Description string
+	Adjustments []analysis.ScenarioAdjustment `gorm:"serializer:json"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Transaction is one parsed row of a project file or an entry added by hand.
+// File rows are rebuilt every time the file or the project settings change;
+// manual entries are never touched by an import.
+type Transaction struct {
+	gorm.Model
+
+	ProjectID   uint      `gorm:"not null;index"`
+	Date        time.Time `gorm:"index"`
+	Amount      float64
+	Description string
+	Category    string
+	SourceRow   int
+	Manual      bool              `gorm:"not null;default:false;index"`
+	Metadata    map[string]string `gorm:"serializer:json"`
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"finview/backend/initializers"
+	"finview/backend/internal/analysis"
+	"finview/backend/internal/i18n"
+	"finview/backend/internal/projects/model"
+	"finview/backend/internal/projects/source"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BudgetLineInput is a budget line as sent by the client. Month accepts a
+// date or a month such as "03/2025", "2025-03" or "mar/2025".
+type BudgetLineInput struct {
+	Month    string
+	Category string
+	Amount   float64
+}
+
+// BudgetImportSettings tell where the budget lives in the project file: a
+// sheet with a header on Line and one row per month and category.
+type BudgetImportSettings struct {
+	Sheet          string
+	Line           int
+	MonthColumn    string
+	CategoryColumn string
+	AmountColumn   string
+}
+
+type BudgetImport struct {
+	Lines       []model.BudgetLine `json:"lines"`
+	Diagnostics ParseDiagnostics   `json:"diagnostics"`
+}
+
+type budgetKey struct {
+	month    time.Time
+	category string
+}
+
+func ListBudget(userID, projectID uint) ([]model.BudgetLine, error) {
+	project, err := findUserProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []model.BudgetLine
+	result := initializers.DB.Where("project_id = ?", project.ID).Order("month, category").Find(&lines)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return lines, nil
+}
+
+// SaveBudgetLines sets the planned amount of each month and category given,
+// creating the lines that do not exist yet.
+func SaveBudgetLines(userID, projectID uint, inputs []BudgetLineInput) ([]model.BudgetLine, error) {
+	project, err := findUserProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	amounts := map[budgetKey]float64{}
+	var order []budgetKey
+	for i, input := range inputs {
+		month, err := parseBudgetMonth(input.Month)
+		if err != nil {
+			return nil, i18n.NewError(i18n.CodeInvalidBudgetMonth, i18n.Params{"line": strconv.Itoa(i + 1), "value": input.Month})
+		}
+		key := budgetKey{month, strings.TrimSpace(input.Category)}
+		if _, ok := amounts[key]; !ok {
+			order = append(order, key)
+		}
+		amounts[key] = input.Amount
+	}
+
+	return upsertBudgetLines(project.ID, order, amounts)
+}
+
+func DeleteBudgetLine(userID, projectID, lineID uint) error {
+	project, err := findUserProject(userID, projectID)
+	if err != nil {
+		return err
+	}
+
+	result := initializers.DB.Unscoped().Where("id = ? AND project_id = ?", lineID, project.ID).Delete(&model.BudgetLine{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return i18n.NewError(i18n.CodeBudgetLineNotFound, nil)
+	}
+	return nil
+}
+
+// ImportBudget reads the budget sheet of the project file and saves its
+// lines like SaveBudgetLines; lines already planned for other months or
+// categories are kept. Rows naming the same month and category are added up.
+func ImportBudget(userID, projectID uint, settings BudgetImportSettings) (*BudgetImport, error) {
+	project, err := findUserProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, _, err := source.Open(project.ArqPath)
+	if err != nil {
+		return nil, sourceError(err, i18n.CodeFileOpenFailed)
+	}
+	defer reader.Close()
+
+	rows, err := loadRows(reader, settings.Sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	var headerRow []string
+	if settings.Line > 0 && settings.Line-1 < len(rows) {
+		headerRow = rows[settings.Line-1]
+	}
+	monthColIndex := columnIndex(headerRow, settings.MonthColumn)
+	categoryColIndex := columnIndex(headerRow, settings.CategoryColumn)
+	amountColIndex := columnIndex(headerRow, settings.AmountColumn)
+	if monthColIndex == -1 {
+		return nil, columnNotFoundError(i18n.CodeBudgetMonthColumnNotFound, settings.MonthColumn, settings.Line)
+	}
+	if settings.CategoryColumn != "" && categoryColIndex == -1 {
+		return nil, columnNotFoundError(i18n.CodeCategoryColumnNotFound, settings.CategoryColumn, settings.Line)
+	}
+	if amountColIndex == -1 {
+		return nil, columnNotFoundError(i18n.CodeBudgetAmountColumnNotFound, settings.AmountColumn, settings.Line)
+	}
+
+	locale := numberLocaleFor(project.ConfigNumberLocale, reader, rows)
+	diagnostics := newParseDiagnostics()
+	amounts := map[budgetKey]float64{}
+	var order []budgetKey
+	for i := settings.Line; i < len(rows); i++ {
+		row := rows[i]
+		line := i + 1
+		if isBlankRow(row) {
+			continue
+		}
+
+		month, err := parseBudgetMonth(cellAt(row, monthColIndex))
+		if err != nil {
+			diagnostics.skip(line, row, SkipReasonBadDate)
+			continue
+		}
+		amount, err := parseNumber(cellAt(row, amountColIndex), locale)
+		if err != nil {
+			diagnostics.skip(line, row, SkipReasonBadNumber)
+			continue
+		}
+
+		key := budgetKey{month, cellAt(row, categoryColIndex)}
+		if _, ok := amounts[key]; !ok {
+			order = append(order, key)
+		}
+		amounts[key] += amount
+		diagnostics.Summary.ParsedRows++
+	}
+
+	lines, err := upsertBudgetLines(project.ID, order, amounts)
+	if err != nil {
+		return nil, err
+	}
+	return &BudgetImport{Lines: lines, Diagnostics: diagnostics}, nil
+}
+
+// loadBudgetEntries returns the budget of the months the range touches.
+func loadBudgetEntries(projectID uint, dateRange DateRange) ([]analysis.BudgetEntry, error) {
+	query := initializers.DB.Where("project_id = ?", projectID)
+	if dateRange.From != nil {
+		query = query.Where("month >= ?", monthStart(*dateRange.From))
+	}
+	if dateRange.To != nil {
+		query = query.Where("month <= ?", *dateRange.To)
+	}
+
+	var lines []model.BudgetLine
+	if err := query.Find(&lines).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]analysis.BudgetEntry, len(lines))
+	for i, line := range lines {
+		entries[i] = analysis.BudgetEntry{Month: line.Month, Category: line.Category, Planned: line.Amount}
+	}
+	return entries, nil
+}
+
+func upsertBudgetLines(projectID uint, keys []budgetKey, amounts map[budgetKey]float64) ([]model.BudgetLine, error) {
+	lines := make([]model.BudgetLine, len(keys))
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		for i, key := range keys {
+			line := &lines[i]
+			result := tx.Where("project_id = ? AND month = ? AND category = ?", projectID, key.month, key.category).Limit(1).Find(line)
+			if result.Error != nil {
+				return result.Error
+			}
+			line.ProjectID = projectID
+			line.Month = key.month
+			line.Category = key.category
+			line.Amount = amounts[key]
+			if err := tx.Save(line).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+func parseBudgetMonth(value string) (time.Time, error) {
+	date, err := parseDateWithFormat(value, "")
+	if err != nil {
+		return time.Time{}, err
+	}
+	return monthStart(date), nil
+}
+
+func monthStart(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
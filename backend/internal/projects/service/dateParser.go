@@ -0,0 +1,265 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Date format overrides that read the date column as Excel serial numbers
+// instead of text.
+const (
+	DateFormatExcel     = "excel"
+	DateFormatExcel1904 = "excel1904"
+)
+
+// Serial numbers outside this range (1970-01-01 to 2099-12-31) are taken as
+// plain numbers when no date format is configured.
+const (
+	minAutoExcelSerial = 25569
+	maxAutoExcelSerial = 73050
+)
+
+var dateLayouts = []string{
+	"2006-01-02",
+	"02/01/2006",
+	"01-02-2006",
+	"02-Jan-2006",
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2/1/2006",
+	"2/1/06",
+	"02.01.2006",
+	"2006/01/02",
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"2-Jan-2006",
+	"2-Jan-06",
+	"2 Jan 2006",
+	"2/Jan/2006",
+	"Jan 2, 2006",
+}
+
+// Month-only layouts, read as the first day of the month.
+var monthLayouts = []string{
+	"Jan/2006",
+	"Jan 2006",
+	"Jan-2006",
+	"Jan/06",
+	"Jan-06",
+	"Jan 06",
+	"01/2006",
+	"2006-01",
+}
+
+// monthNames maps Portuguese and English month names and abbreviations to the
+// abbreviation Go layouts understand.
+var monthNames = map[string]string{
+	"jan": "Jan", "janeiro": "Jan", "january": "Jan",
+	"fev": "Feb", "fevereiro": "Feb", "feb": "Feb", "february": "Feb",
+	"mar": "Mar", "março": "Mar", "marco": "Mar", "march": "Mar",
+	"abr": "Apr", "abril": "Apr", "apr": "Apr", "april": "Apr",
+	"mai": "May", "maio": "May", "may": "May",
+	"jun": "Jun", "junho": "Jun", "june": "Jun",
+	"jul": "Jul", "julho": "Jul", "july": "Jul",
+	"ago": "Aug", "agosto": "Aug", "aug": "Aug", "august": "Aug",
+	"set": "Sep", "setembro": "Sep", "sep": "Sep", "sept": "Sep", "september": "Sep",
+	"out": "Oct", "outubro": "Oct", "oct": "Oct", "october": "Oct",
+	"nov": "Nov", "novembro": "Nov", "november": "Nov",
+	"dez": "Dec", "dezembro": "Dec", "dec": "Dec", "december": "Dec",
+}
+
+// Words dropped between the parts of a written date, as in "15 de março de 2024".
+var dateFillerWords = map[string]bool{"de": true, "of": true}
+
+// parseDate reads a date in any of the known layouts, including month names
+// in Portuguese or English. Month-only values fall on the first of the month.
+func parseDate(dateStr string) (time.Time, error) {
+	value := strings.TrimSpace(dateStr)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	normalized := normalizeMonthNames(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, normalized); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range monthLayouts {
+		if t, err := time.Parse(layout, normalized); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+}
+
+// parseDateWithFormat reads a date column cell. With no format configured it
+// falls back from parseDate to Excel serial numbers in a plausible range,
+// which is how spreadsheets saved as CSV often carry their dates.
+func parseDateWithFormat(dateStr, format string) (time.Time, error) {
+	switch format {
+	case "":
+		if t, err := parseDate(dateStr); err == nil {
+			return t, nil
+		}
+		serial, err := strconv.ParseFloat(strings.TrimSpace(dateStr), 64)
+		if err != nil || serial < minAutoExcelSerial || serial > maxAutoExcelSerial {
+			return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+		}
+		return excelSerialToTime(serial, false)
+	case DateFormatExcel, DateFormatExcel1904:
+		serial, err := strconv.ParseFloat(strings.TrimSpace(dateStr), 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+		}
+		return excelSerialToTime(serial, format == DateFormatExcel1904)
+	}
+
+	layout, err := dateFormatLayout(format)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(layout, normalizeMonthNames(strings.TrimSpace(dateStr)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+	}
+	return t, nil
+}
+
+// excelSerialToTime converts a serial day number. In the 1900 system Excel
+// counts a non-existent 1900-02-29, so serials from 61 on are one day ahead
+// of the calendar; the fraction is the time of day.
+func excelSerialToTime(serial float64, date1904 bool) (time.Time, error) {
+	if serial < 1 || math.IsNaN(serial) || math.IsInf(serial, 0) {
+		return time.Time{}, fmt.Errorf("invalid Excel date: %v", serial)
+	}
+
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	} else if serial < 61 {
+		epoch = epoch.AddDate(0, 0, 1)
+	}
+
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 24 * 60 * 60)
+	return epoch.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second), nil
+}
+
+// IsValidDate reports whether a date typed in the settings can be read.
+func IsValidDate(value string) bool {
+	_, err := parseDate(value)
+	return err == nil
+}
+
+// IsSupportedDateFormat reports whether a project date format override can be
+// used. An empty format means automatic detection.
+func IsSupportedDateFormat(format string) bool {
+	if format == "" || format == DateFormatExcel || format == DateFormatExcel1904 {
+		return true
+	}
+	_, err := dateFormatLayout(format)
+	return err == nil
+}
+
+// dateFormatLayout turns a pattern such as "DD/MM/YYYY" or "MMM/YY" into a Go
+// layout. D is the day, M the month (MMM and MMMM its name), Y the year, and
+// HH, mm and ss the time; "mm" right after a colon is minutes, otherwise the
+// month. Anything that is not a letter is kept as a separator.
+func dateFormatLayout(format string) (string, error) {
+	var layout strings.Builder
+	runes := []rune(format)
+	hasMonth, hasYear := false, false
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if !unicode.IsLetter(r) {
+			layout.WriteRune(r)
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(runes) && unicode.ToLower(runes[j]) == unicode.ToLower(r) {
+			j++
+		}
+		token := strings.ToLower(string(runes[i:j]))
+		afterColon := i > 0 && runes[i-1] == ':'
+
+		switch {
+		case token == "d":
+			layout.WriteString("2")
+		case token == "dd":
+			layout.WriteString("02")
+		case token == "mm" && afterColon:
+			layout.WriteString("04")
+		case token == "m":
+			layout.WriteString("1")
+			hasMonth = true
+		case token == "mm":
+			layout.WriteString("01")
+			hasMonth = true
+		case token == "mmm" || token == "mmmm":
+			layout.WriteString("Jan")
+			hasMonth = true
+		case token == "yy":
+			layout.WriteString("06")
+			hasYear = true
+		case token == "yyyy":
+			layout.WriteString("2006")
+			hasYear = true
+		case token == "hh":
+			layout.WriteString("15")
+		case token == "ss":
+			layout.WriteString("05")
+		default:
+			return "", fmt.Errorf("unsupported date format: %s", format)
+		}
+		i = j
+	}
+
+	// Without a month and a year the value cannot be placed on the calendar.
+	if !hasMonth || !hasYear {
+		return "", fmt.Errorf("unsupported date format: %s", format)
+	}
+	return layout.String(), nil
+}
+
+// normalizeMonthNames replaces month names with their English abbreviation
+// and drops filler words, so "15 de março de 2024" becomes "15 Mar 2024".
+func normalizeMonthNames(value string) string {
+	var out strings.Builder
+	var word []rune
+
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		lower := strings.ToLower(string(word))
+		if month, ok := monthNames[lower]; ok {
+			out.WriteString(month)
+		} else if !dateFillerWords[lower] {
+			out.WriteString(string(word))
+		}
+		word = word[:0]
+	}
+
+	for _, r := range value {
+		if unicode.IsLetter(r) {
+			word = append(word, r)
+			continue
+		}
+		flush()
+		out.WriteRune(r)
+	}
+	flush()
+
+	return strings.Join(strings.Fields(out.String()), " ")
+}
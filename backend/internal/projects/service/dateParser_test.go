@@ -0,0 +1,144 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"2024-03-15", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"15/03/2024", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"5/3/24", time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"15.03.2024", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"15/03/2024 14:30", time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC)},
+		{"15-Mar-2024", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"15 de março de 2024", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"15 Fev 2024", time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)},
+		{"March 15, 2024", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"mar/2024", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"Dezembro 2023", time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)},
+		{"03/2024", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{" 2024-03 ", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseDate(tt.input)
+			if err != nil {
+				t.Fatalf("parseDate(%q) error = %v", tt.input, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseDate(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "yesterday", "32/01/2024", "45366"} {
+		if got, err := parseDate(input); err == nil {
+			t.Errorf("parseDate(%q) = %v, want an error", input, got)
+		}
+	}
+}
+
+func TestParseDateWithFormat(t *testing.T) {
+	tests := []struct {
+		input  string
+		format string
+		want   time.Time
+	}{
+		// Without a format, serials in a plausible range are Excel dates.
+		{"45366", "", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"45366.5", "", time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{"45366", DateFormatExcel, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"43904", DateFormatExcel1904, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"03/15/2024", "MM/DD/YYYY", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"15/03/24", "DD/MM/YY", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"mar/24", "MMM/YY", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024-03-15 08:05:09", "YYYY-MM-DD HH:mm:ss", time.Date(2024, 3, 15, 8, 5, 9, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.input, func(t *testing.T) {
+			got, err := parseDateWithFormat(tt.input, tt.format)
+			if err != nil {
+				t.Fatalf("parseDateWithFormat(%q, %q) error = %v", tt.input, tt.format, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseDateWithFormat(%q, %q) = %v, want %v", tt.input, tt.format, got, tt.want)
+			}
+		})
+	}
+
+	invalid := []struct{ input, format string }{
+		{"12", ""},
+		{"99999", ""},
+		{"15/03/2024", "MM/DD/YYYY"},
+		{"abc", DateFormatExcel},
+		{"0", DateFormatExcel},
+	}
+	for _, tt := range invalid {
+		if got, err := parseDateWithFormat(tt.input, tt.format); err == nil {
+			t.Errorf("parseDateWithFormat(%q, %q) = %v, want an error", tt.input, tt.format, got)
+		}
+	}
+}
+
+func TestExcelSerialToTime(t *testing.T) {
+	tests := []struct {
+		serial   float64
+		date1904 bool
+		want     time.Time
+	}{
+		{1, false, time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{59, false, time.Date(1900, 2, 28, 0, 0, 0, 0, time.UTC)},
+		// Serial 60 is Excel's 1900-02-29, which never existed.
+		{61, false, time.Date(1900, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{25569, false, time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{45366.75, false, time.Date(2024, 3, 15, 18, 0, 0, 0, time.UTC)},
+		{1, true, time.Date(1904, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{43904, true, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		got, err := excelSerialToTime(tt.serial, tt.date1904)
+		if err != nil {
+			t.Fatalf("excelSerialToTime(%v, %v) error = %v", tt.serial, tt.date1904, err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("excelSerialToTime(%v, %v) = %v, want %v", tt.serial, tt.date1904, got, tt.want)
+		}
+	}
+}
+
+func TestDateFormatLayout(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"DD/MM/YYYY", "02/01/2006"},
+		{"d/m/yy", "2/1/06"},
+		{"MMM/YYYY", "Jan/2006"},
+		{"MMMM YYYY", "Jan 2006"},
+		{"YYYY-MM-DD HH:mm:ss", "2006-01-02 15:04:05"},
+		{"DD.MM.YYYY HH:mm", "02.01.2006 15:04"},
+	}
+	for _, tt := range tests {
+		got, err := dateFormatLayout(tt.format)
+		if err != nil {
+			t.Fatalf("dateFormatLayout(%q) error = %v", tt.format, err)
+		}
+		if got != tt.want {
+			t.Errorf("dateFormatLayout(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	for _, format := range []string{"", "DD/MM", "MM/DD", "YYYY", "QQ/YYYY", "DD/MM/YYY"} {
+		if got, err := dateFormatLayout(format); err == nil {
+			t.Errorf("dateFormatLayout(%q) = %q, want an error", format, got)
+		}
+	}
+}
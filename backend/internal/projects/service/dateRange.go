@@ -0,0 +1,130 @@
+package service
+
+import (
+	"finview/backend/internal/analysis"
+	"finview/backend/internal/i18n"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateRange limits an analysis to the points between From and To, both
+// inclusive days. A nil bound is open.
+type DateRange struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// ResolveDateRange builds the range from explicit from/to dates or from a
+// preset relative to today: mtd, qtd, ytd, last_month, last_quarter,
+// last_year, or last_<N>d / last_<N>m / last_<N>y for the trailing N days,
+// months or years up to today. Explicit dates override the matching bound of
+// the preset.
+func ResolveDateRange(preset, from, to string, today time.Time) (DateRange, error) {
+	var dateRange DateRange
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	if preset != "" {
+		start, end, err := presetRange(preset, today)
+		if err != nil {
+			return DateRange{}, err
+		}
+		dateRange.From, dateRange.To = &start, &end
+	}
+
+	if from != "" {
+		date, err := parseDate(from)
+		if err != nil {
+			return DateRange{}, i18n.NewError(i18n.CodeInvalidFromDate, i18n.Params{"value": from})
+		}
+		dateRange.From = &date
+	}
+	if to != "" {
+		date, err := parseDate(to)
+		if err != nil {
+			return DateRange{}, i18n.NewError(i18n.CodeInvalidToDate, i18n.Params{"value": to})
+		}
+		dateRange.To = &date
+	}
+
+	if dateRange.From != nil && dateRange.To != nil && dateRange.To.Before(*dateRange.From) {
+		return DateRange{}, i18n.NewError(i18n.CodeInvalidDateRange, nil)
+	}
+	return dateRange, nil
+}
+
+func presetRange(preset string, today time.Time) (time.Time, time.Time, error) {
+	year, month, _ := today.Date()
+	quarterStart := time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, time.UTC)
+
+	switch preset {
+	case "mtd":
+		return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC), today, nil
+	case "qtd":
+		return quarterStart, today, nil
+	case "ytd":
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), today, nil
+	case "last_month":
+		start := time.Date(year, month-1, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1), nil
+	case "last_quarter":
+		start := quarterStart.AddDate(0, -3, 0)
+		return start, quarterStart.AddDate(0, 0, -1), nil
+	case "last_year":
+		return time.Date(year-1, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(year-1, time.December, 31, 0, 0, 0, 0, time.UTC), nil
+	}
+
+	if amount, ok := strings.CutPrefix(preset, "last_"); ok && len(amount) > 1 {
+		n, err := strconv.Atoi(amount[:len(amount)-1])
+		if err == nil && n > 0 {
+			switch amount[len(amount)-1] {
+			case 'd':
+				return today.AddDate(0, 0, 1-n), today, nil
+			case 'm':
+				return today.AddDate(0, -n, 1), today, nil
+			case 'y':
+				return today.AddDate(-n, 0, 1), today, nil
+			}
+		}
+	}
+	return time.Time{}, time.Time{}, i18n.NewError(i18n.CodeInvalidRangePreset, i18n.Params{"value": preset})
+}
+
+// contains reports whether the date falls on a day inside the range.
+func (r DateRange) contains(date time.Time) bool {
+	if r.From != nil && date.Before(*r.From) {
+		return false
+	}
+	if r.To != nil && !date.Before(r.To.AddDate(0, 0, 1)) {
+		return false
+	}
+	return true
+}
+
+// filterSeries keeps the points inside the range and returns the sum of the
+// points before it, the balance the range opens with.
+func (r DateRange) filterSeries(series []analysis.TimeSeriesDataPoint) ([]analysis.TimeSeriesDataPoint, float64) {
+	if r.From == nil && r.To == nil {
+		return series, 0
+	}
+
+	var filtered []analysis.TimeSeriesDataPoint
+	openingBalance := 0.0
+	for _, point := range series {
+		if r.From != nil && point.Date.Before(*r.From) {
+			openingBalance += point.Value
+			continue
+		}
+		if r.contains(point.Date) {
+			filtered = append(filtered, point)
+		}
+	}
+	return filtered, openingBalance
+}
+
+func formatRangeBound(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format("2006-01-02")
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"errors"
+	"finview/backend/internal/i18n"
+	"finview/backend/internal/projects/source"
+	"strconv"
+)
+
+var errProjectNotFound = i18n.NewError(i18n.CodeProjectNotFound, nil)
+
+// ImportErrorCodes are the codes a project import can fail with. The error is
+// stored on the project and every analysis answers with it until the file or
+// the project settings are fixed.
+var ImportErrorCodes = []string{
+	i18n.CodeFileOpenFailed,
+	i18n.CodeInvalidFile,
+	i18n.CodeEmptyFile,
+	i18n.CodeLegacyXLS,
+	i18n.CodeUnsupportedFileFormat,
+	i18n.CodeOFXMissingRoot,
+	i18n.CodeOFXUnclosedTrn,
+	i18n.CodeSheetNotFound,
+	i18n.CodeProjectNotConfigured,
+	i18n.CodeValueColumnNotFound,
+	i18n.CodeInflowColumnNotFound,
+	i18n.CodeOutflowColumnNotFound,
+	i18n.CodeCategoryColumnNotFound,
+	i18n.CodeDescriptionColumnNotFound,
+	i18n.CodeInvalidOpeningDate,
+	i18n.CodeInvalidOpeningCell,
+	i18n.CodeOpeningCellNotNumber,
+}
+
+// sourceError gives the reason a project file could not be read its own code;
+// other failures get fallbackCode with the error as detail.
+func sourceError(err error, fallbackCode string) error {
+	switch {
+	case errors.Is(err, source.ErrEmptyFile):
+		return i18n.NewError(i18n.CodeEmptyFile, nil)
+	case errors.Is(err, source.ErrLegacyXLS):
+		return i18n.NewError(i18n.CodeLegacyXLS, nil)
+	case errors.Is(err, source.ErrUnsupportedFormat):
+		return i18n.NewError(i18n.CodeUnsupportedFileFormat, nil)
+	case errors.Is(err, source.ErrOFXMissingRoot):
+		return i18n.NewError(i18n.CodeOFXMissingRoot, nil)
+	case errors.Is(err, source.ErrOFXUnclosedTrn):
+		return i18n.NewError(i18n.CodeOFXUnclosedTrn, nil)
+	}
+	return i18n.NewError(fallbackCode, i18n.Params{"detail": err.Error()})
+}
+
+func sheetNotFoundError(sheet string) error {
+	return i18n.NewError(i18n.CodeSheetNotFound, i18n.Params{"sheet": sheet})
+}
+
+func columnNotFoundError(code, column string, line int) error {
+	return i18n.NewError(code, i18n.Params{"column": column, "line": strconv.Itoa(line)})
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"finview/backend/internal/analysis"
+	"finview/backend/internal/i18n"
+)
+
+// ForecastOptions select how far ahead and with which model the project is
+// forecast. The zero Method lets the history decide.
+type ForecastOptions struct {
+	Horizon int
+	Method  analysis.ForecastMethod
+}
+
+// GetProjectForecast projects the monthly cash flow and balance of a dated
+// project from its whole history.
+func GetProjectForecast(userID, projectID uint, options ForecastOptions) (*analysis.Forecast, error) {
+	project, series, err := loadProjectSeries(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if !project.ImportDated {
+		return nil, i18n.NewError(i18n.CodeForecastRequiresDates, nil)
+	}
+
+	if options.Horizon <= 0 {
+		options.Horizon = analysis.DefaultForecastHorizon
+	}
+	return analysis.CalculateForecast(series, projectOpeningBalance(*project, series), options.Horizon, options.Method)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"finview/backend/initializers"
+	"finview/backend/internal/analysis"
+	"finview/backend/internal/projects/model"
+)
+
+// Where an effective health policy comes from.
+const (
+	HealthPolicyScopeProject = "project"
+	HealthPolicyScopeUser    = "user"
+	HealthPolicyScopeDefault = "default"
+)
+
+type EffectiveHealthPolicy struct {
+	Scope  string                `json:"scope"`
+	Policy analysis.HealthPolicy `json:"policy"`
+}
+
+// GetUserHealthPolicy returns the policy applied to the user's projects that
+// have none of their own.
+func GetUserHealthPolicy(userID uint) (*EffectiveHealthPolicy, error) {
+	return effectiveHealthPolicy(userID, nil)
+}
+
+func SaveUserHealthPolicy(userID uint, rules analysis.HealthPolicy) (*EffectiveHealthPolicy, error) {
+	if err := saveHealthPolicy(userID, nil, rules); err != nil {
+		return nil, err
+	}
+	return effectiveHealthPolicy(userID, nil)
+}
+
+// DeleteUserHealthPolicy goes back to the default policy.
+func DeleteUserHealthPolicy(userID uint) error {
+	return deleteHealthPolicy(userID, nil)
+}
+
+func GetProjectHealthPolicy(userID, projectID uint) (*EffectiveHealthPolicy, error) {
+	project, err := findUserProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return effectiveHealthPolicy(userID, &project.ID)
+}
+
+func SaveProjectHealthPolicy(userID, projectID uint, rules analysis.HealthPolicy) (*EffectiveHealthPolicy, error) {
+	project, err := findUserProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveHealthPolicy(userID, &project.ID, rules); err != nil {
+		return nil, err
+	}
+	return effectiveHealthPolicy(userID, &project.ID)
+}
+
+// DeleteProjectHealthPolicy makes the project follow the user's policy again.
+func DeleteProjectHealthPolicy(userID, projectID uint) error {
+	project, err := findUserProject(userID, projectID)
+	if err != nil {
+		return err
+	}
+	return deleteHealthPolicy(userID, &project.ID)
+}
+
+// effectiveHealthPolicy resolves the project policy, then the user policy,
+// then the default one. A nil projectID only looks at the user policy.
+func effectiveHealthPolicy(userID uint, projectID *uint) (*EffectiveHealthPolicy, error) {
+	if projectID != nil {
+		policy, err := findHealthPolicy(userID, projectID)
+		if err != nil {
+			return nil, err
+		}
+		if policy != nil {
+			return &EffectiveHealthPolicy{Scope: HealthPolicyScopeProject, Policy: policy.Rules}, nil
+		}
+	}
+
+	policy, err := findHealthPolicy(userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil {
+		return &EffectiveHealthPolicy{Scope: HealthPolicyScopeUser, Policy: policy.Rules}, nil
+	}
+	return &EffectiveHealthPolicy{Scope: HealthPolicyScopeDefault, Policy: analysis.DefaultHealthPolicy()}, nil
+}
+
+func findHealthPolicy(userID uint, projectID *uint) (*model.HealthPolicy, error) {
+	query := initializers.DB.Where("user_id = ?", userID)
+	if projectID == nil {
+		query = query.Where("project_id IS NULL")
+	} else {
+		query = query.Where("project_id = ?", *projectID)
+	}
+
+	// Find rather than First: a missing policy is the common case, not an error.
+	var policy model.HealthPolicy
+	result := query.Limit(1).Find(&policy)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &policy, nil
+}
+
+func saveHealthPolicy(userID uint, projectID *uint, rules analysis.HealthPolicy) error {
+	policy, err := findHealthPolicy(userID, projectID)
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		policy = &model.HealthPolicy{UserID: userID, ProjectID: projectID}
+	}
+	policy.Rules = rules
+	return initializers.DB.Save(policy).Error
+}
+
+func deleteHealthPolicy(userID uint, projectID *uint) error {
+	query := initializers.DB.Unscoped().Where("user_id = ?", userID)
+	if projectID == nil {
+		query = query.Where("project_id IS NULL")
+	} else {
+		query = query.Where("project_id = ?", *projectID)
+	}
+	return query.Delete(&model.HealthPolicy{}).Error
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"finview/backend/initializers"
+	"finview/backend/internal/analysis"
+	"finview/backend/internal/i18n"
+	"finview/backend/internal/projects/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const importBatchSize = 500
+
+// importProjectTransactions re-parses the project file and replaces the
+// project's file transactions with the result; manual entries are kept. A
+// file that cannot be parsed with the current settings is not an error here:
+// the message is stored on the project and reported when an analysis is
+// requested.
+func importProjectTransactions(project *model.Project) error {
+	data, parseErr := loadProjectData(*project)
+
+	now := time.Now()
+	project.ImportedAt = &now
+	project.ImportError = ""
+	project.ImportErrorCode = ""
+	project.ImportErrorParams = nil
+	project.ImportDated = false
+	project.ImportColumn = ""
+	project.ImportSummary = analysis.ParseSummary{}
+	project.ImportOpeningBalance = nil
+
+	var transactions []model.Transaction
+	if parseErr != nil {
+		project.ImportError = parseErr.Error()
+		var coded *i18n.Error
+		if errors.As(parseErr, &coded) {
+			project.ImportErrorCode = coded.Code
+			project.ImportErrorParams = coded.Params
+		}
+	} else {
+		project.ImportDated = data.Dated
+		project.ImportColumn = data.Column
+		project.ImportSummary = data.Diagnostics.Summary
+		project.ImportOpeningBalance = data.OpeningBalance
+
+		for _, row := range data.Rows {
+			transactions = append(transactions, model.Transaction{
+				ProjectID:   project.ID,
+				Date:        row.Point.Date,
+				Amount:      row.Point.Value,
+				Description: row.Point.Description,
+				Category:    row.Point.Category,
+				SourceRow:   row.Line,
+				Metadata:    row.Point.Metadata,
+			})
+		}
+	}
+
+	return initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("project_id = ? AND manual = ?", project.ID, false).Delete(&model.Transaction{}).Error; err != nil {
+			return err
+		}
+		if len(transactions) > 0 {
+			if err := tx.CreateInBatches(transactions, importBatchSize).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(project).Error
+	})
+}
+
+// loadProjectTransactions returns the imported and manual transactions in the
+// order the analysis expects: chronological for dated imports, file order
+// followed by manual entries otherwise.
+func loadProjectTransactions(project model.Project) ([]model.Transaction, error) {
+	order := "manual, source_row, id"
+	if project.ImportDated {
+		order = "date, manual, source_row, id"
+	}
+
+	var transactions []model.Transaction
+	result := initializers.DB.Where("project_id = ?", project.ID).Order(order).Find(&transactions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return transactions, nil
+}
+
+func transactionsToSeries(transactions []model.Transaction) []analysis.TimeSeriesDataPoint {
+	series := make([]analysis.TimeSeriesDataPoint, len(transactions))
+	for i, t := range transactions {
+		series[i] = analysis.TimeSeriesDataPoint{
+			Date:        t.Date,
+			Value:       t.Amount,
+			Category:    t.Category,
+			Description: t.Description,
+			Metadata:    t.Metadata,
+		}
+	}
+	return series
+}
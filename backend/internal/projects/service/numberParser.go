@@ -0,0 +1,120 @@
+package service
+
+import (
+	"finview/backend/internal/projects/source"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NumberLocale describes how a project file writes its numbers. Whitespace,
+// including non-breaking spaces, is always accepted as a thousands separator.
+type NumberLocale struct {
+	Code      string
+	Decimal   rune
+	Thousands string
+}
+
+var numberLocales = map[string]NumberLocale{
+	"pt-BR": {Code: "pt-BR", Decimal: ',', Thousands: "."},
+	"pt-PT": {Code: "pt-PT", Decimal: ',', Thousands: "."},
+	"es-ES": {Code: "es-ES", Decimal: ',', Thousands: "."},
+	"de-DE": {Code: "de-DE", Decimal: ',', Thousands: "."},
+	"fr-FR": {Code: "fr-FR", Decimal: ',', Thousands: ""},
+	"en-US": {Code: "en-US", Decimal: '.', Thousands: ","},
+	"en-GB": {Code: "en-GB", Decimal: '.', Thousands: ","},
+	"de-CH": {Code: "de-CH", Decimal: '.', Thousands: "'’"},
+}
+
+// Currency markers stripped before parsing, longest first so "US$" is not
+// left as "US".
+var currencyMarkers = []string{"US$", "R$", "U$", "BRL", "USD", "EUR", "GBP", "€", "£", "$", "¥"}
+
+func IsSupportedNumberLocale(code string) bool {
+	_, ok := numberLocales[code]
+	return ok
+}
+
+// numberLocaleFor returns the configured locale or, when none is configured,
+// the one implied by the decimal separator the file is detected to use.
+func numberLocaleFor(code string, reader source.SourceReader, rows [][]string) NumberLocale {
+	if locale, ok := numberLocales[code]; ok {
+		return locale
+	}
+	if source.DecimalSeparator(reader, rows) == '.' {
+		return numberLocales["en-US"]
+	}
+	return numberLocales["pt-BR"]
+}
+
+// parseNumber reads an amount written in the given locale. Besides a leading
+// sign it understands accounting negatives "(1.234,56)", a trailing minus
+// "1.234,56-", the bank statement suffixes "D" (debit, negative) and "C"
+// (credit, positive), and currency symbols or codes anywhere in the cell.
+func parseNumber(valStr string, locale NumberLocale) (float64, error) {
+	clean := strings.TrimSpace(valStr)
+	negative := false
+
+	if strings.HasPrefix(clean, "(") && strings.HasSuffix(clean, ")") {
+		negative = true
+		clean = strings.TrimSpace(clean[1 : len(clean)-1])
+	}
+
+	if suffix, rest, ok := cutIndicatorSuffix(clean); ok {
+		negative = negative || suffix == 'D'
+		clean = rest
+	}
+
+	for _, marker := range currencyMarkers {
+		clean = strings.ReplaceAll(clean, marker, "")
+	}
+	clean = strings.TrimSpace(clean)
+
+	if strings.HasSuffix(clean, "-") {
+		negative = true
+		clean = strings.TrimSpace(strings.TrimSuffix(clean, "-"))
+	}
+	clean = strings.Replace(clean, "−", "-", 1)
+
+	var b strings.Builder
+	for _, r := range clean {
+		switch {
+		case r == locale.Decimal:
+			b.WriteRune('.')
+		case strings.ContainsRune(locale.Thousands, r), unicode.IsSpace(r):
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	value, err := strconv.ParseFloat(b.String(), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("unable to parse number: %s", valStr)
+	}
+	if negative {
+		value = -math.Abs(value)
+	}
+	return value, nil
+}
+
+// cutIndicatorSuffix removes a trailing "D" or "C" that follows a digit,
+// optionally separated by a space.
+func cutIndicatorSuffix(value string) (rune, string, bool) {
+	upper := strings.ToUpper(value)
+	if len(upper) < 2 {
+		return 0, value, false
+	}
+
+	suffix := rune(upper[len(upper)-1])
+	if suffix != 'D' && suffix != 'C' {
+		return 0, value, false
+	}
+
+	rest := strings.TrimSpace(value[:len(value)-1])
+	if rest == "" || !unicode.IsDigit(rune(rest[len(rest)-1])) {
+		return 0, value, false
+	}
+	return suffix, rest, true
+}
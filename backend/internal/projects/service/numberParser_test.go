@@ -0,0 +1,53 @@
+package service
+
+import "testing"
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		input  string
+		locale string
+		want   float64
+	}{
+		{"1234", "pt-BR", 1234},
+		{"1.234,56", "pt-BR", 1234.56},
+		{"1,234.56", "en-US", 1234.56},
+		// The same digits read differently depending on the locale.
+		{"1.234", "pt-BR", 1234},
+		{"1.234", "en-US", 1.234},
+		{"1,234", "pt-BR", 1.234},
+		{"1,234", "en-US", 1234},
+		{"1.234.567,8", "de-DE", 1234567.8},
+		{"1 234,56", "fr-FR", 1234.56},
+		{"1 234,56", "fr-FR", 1234.56},
+		{"1'234.50", "de-CH", 1234.5},
+		{"-1.234,56", "pt-BR", -1234.56},
+		{"−12,5", "pt-BR", -12.5},
+		{"(1.234,56)", "pt-BR", -1234.56},
+		{"1.234,56-", "pt-BR", -1234.56},
+		{"R$ 1.234,56", "pt-BR", 1234.56},
+		{"US$ 1,234.56", "en-US", 1234.56},
+		{"150,00 D", "pt-BR", -150},
+		{"150,00C", "pt-BR", 150},
+		{"(€ 20.00)", "en-GB", -20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale+" "+tt.input, func(t *testing.T) {
+			got, err := parseNumber(tt.input, numberLocales[tt.locale])
+			if err != nil {
+				t.Fatalf("parseNumber(%q) error = %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseNumber(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNumberInvalid(t *testing.T) {
+	for _, input := range []string{"", "abc", "R$", "1,2,3.4.5", "NaN", "Inf"} {
+		if got, err := parseNumber(input, numberLocales["en-US"]); err == nil {
+			t.Errorf("parseNumber(%q) = %v, want an error", input, got)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"finview/backend/internal/analysis"
+	"finview/backend/internal/i18n"
+	"finview/backend/internal/projects/model"
+	"finview/backend/internal/projects/source"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// projectOpeningBalance returns the balance before the first transaction. The
+// configured balance is the one at the end of its as-of date, so the
+// transactions up to that day are already part of it.
+func projectOpeningBalance(project model.Project, series []analysis.TimeSeriesDataPoint) float64 {
+	openingBalance := project.ConfigOpeningBalance
+	if project.ConfigOpeningBalanceCell != "" && project.ImportOpeningBalance != nil {
+		openingBalance = *project.ImportOpeningBalance
+	}
+
+	if project.ConfigOpeningBalanceDate != nil {
+		dayAfter := project.ConfigOpeningBalanceDate.AddDate(0, 0, 1)
+		for _, point := range series {
+			if point.Date.Before(dayAfter) {
+				openingBalance -= point.Value
+			}
+		}
+	}
+	return openingBalance
+}
+
+// readOpeningBalanceCell reads the amount in ConfigOpeningBalanceCell, on the
+// configured sheet unless the reference names one.
+func readOpeningBalanceCell(reader source.SourceReader, project model.Project, locale NumberLocale) (float64, error) {
+	sheet, cell := splitCellReference(project.ConfigOpeningBalanceCell, project.ConfigSheet)
+	col, line, err := excelize.CellNameToCoordinates(cell)
+	if err != nil {
+		return 0, i18n.NewError(i18n.CodeInvalidOpeningCell, i18n.Params{"value": project.ConfigOpeningBalanceCell})
+	}
+
+	value := ""
+	err = reader.Rows(sheet, func(l int, row []string) bool {
+		if l < line {
+			return true
+		}
+		value = cellAt(row, col-1)
+		return false
+	})
+	if errors.Is(err, source.ErrSheetNotFound) {
+		return 0, sheetNotFoundError(sheet)
+	}
+	if err != nil {
+		return 0, sourceError(err, i18n.CodeFileOpenFailed)
+	}
+
+	amount, err := parseNumber(value, locale)
+	if err != nil {
+		return 0, i18n.NewError(i18n.CodeOpeningCellNotNumber, i18n.Params{"value": project.ConfigOpeningBalanceCell})
+	}
+	return amount, nil
+}
+
+// splitCellReference separates "Resumo!B2" or "'Fluxo de caixa'!B2" into
+// sheet and cell. A bare cell is on defaultSheet.
+func splitCellReference(reference, defaultSheet string) (string, string) {
+	i := strings.LastIndex(reference, "!")
+	if i == -1 {
+		return defaultSheet, strings.TrimSpace(reference)
+	}
+	sheet := strings.Trim(strings.TrimSpace(reference[:i]), "'")
+	return sheet, strings.TrimSpace(reference[i+1:])
+}
+
+func IsValidCellReference(reference string) bool {
+	_, cell := splitCellReference(reference, "")
+	_, _, err := excelize.CellNameToCoordinates(cell)
+	return err == nil
+}
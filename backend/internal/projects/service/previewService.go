@@ -0,0 +1,76 @@
+package service
+
+import (
+	"finview/backend/initializers"
+	"finview/backend/internal/projects/model"
+	"time"
+)
+
+const (
+	defaultPreviewLimit = 20
+	maxPreviewLimit     = 500
+)
+
+type PreviewRow struct {
+	Line        int               `json:"line"`
+	Date        *time.Time        `json:"date,omitempty"`
+	Value       float64           `json:"value"`
+	Category    string            `json:"category,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+type ProjectPreview struct {
+	Column      string           `json:"column"`
+	Rows        []PreviewRow     `json:"rows"`
+	Diagnostics ParseDiagnostics `json:"diagnostics"`
+}
+
+// GetProjectPreview parses the project file with its current settings and
+// returns the first parsed rows along with every row that was skipped.
+func GetProjectPreview(userID, projectID uint, limit int) (*ProjectPreview, error) {
+	var project model.Project
+
+	result := initializers.DB.First(&project, "id = ? AND user_id = ?", projectID, userID)
+	if result.Error != nil {
+		return nil, errProjectNotFound
+	}
+
+	if limit <= 0 {
+		limit = defaultPreviewLimit
+	}
+	if limit > maxPreviewLimit {
+		limit = maxPreviewLimit
+	}
+
+	data, err := loadProjectData(project)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &ProjectPreview{
+		Column:      data.Column,
+		Rows:        []PreviewRow{},
+		Diagnostics: data.Diagnostics,
+	}
+	for _, row := range data.Rows {
+		if len(preview.Rows) == limit {
+			break
+		}
+
+		previewRow := PreviewRow{
+			Line:        row.Line,
+			Value:       row.Point.Value,
+			Category:    row.Point.Category,
+			Description: row.Point.Description,
+			Metadata:    row.Point.Metadata,
+		}
+		if data.Dated {
+			date := row.Point.Date
+			previewRow.Date = &date
+		}
+		preview.Rows = append(preview.Rows, previewRow)
+	}
+
+	return preview, nil
+}
@@ -1,35 +1,58 @@
 package service
 
 import (
+	"errors"
 	"finview/backend/initializers"
 	"finview/backend/internal/analysis"
+	"finview/backend/internal/i18n"
 	"finview/backend/internal/projects/model"
+	"finview/backend/internal/projects/source"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"os"
 	"path/filepath"
-	"strconv"
+	"slices"
 	"time"
 
-	"strings"
-
-	"github.com/xuri/excelize/v2"
 	"gorm.io/gorm"
 )
 
 const uploadDir = "./uploads"
 
-func CreateProject(file *multipart.FileHeader, userID uint, projectName string) (*model.Project, error) {
+// inspectStoredFile sniffs an uploaded file, checks it against the source type
+// the user selected (if any) and returns its type, sheet names and the
+// settings detected from its content.
+func inspectStoredFile(path, requested string) (string, []string, *DetectedSettings, error) {
+	reader, fileType, err := source.Open(path)
+	if err != nil {
+		return "", nil, nil, sourceError(err, i18n.CodeInvalidFile)
+	}
+	defer reader.Close()
+
+	if requested != "" && requested != fileType {
+		return "", nil, nil, i18n.NewError(i18n.CodeFileTypeMismatch, i18n.Params{"type": requested})
+	}
+
+	detected, err := detectSettings(reader)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return fileType, reader.Sheets(), detected, nil
+}
+
+// CreateProject stores the uploaded file and pre-fills the project settings
+// with the detected sheet, header line and columns when detection succeeds.
+func CreateProject(file *multipart.FileHeader, userID uint, projectName, sourceType string) (*model.Project, *DetectedSettings, error) {
 	src, err := file.Open()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer src.Close()
 
 	userUploadDir := filepath.Join(uploadDir, fmt.Sprintf("user_%d", userID))
 	if err := os.MkdirAll(userUploadDir, os.ModePerm); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	ext := filepath.Ext(file.Filename)
@@ -38,12 +61,18 @@ func CreateProject(file *multipart.FileHeader, userID uint, projectName string)
 
 	dst, err := os.Create(storagePath)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer dst.Close()
 
 	if _, err = io.Copy(dst, src); err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	sourceType, sheets, detected, err := inspectStoredFile(storagePath, sourceType)
+	if err != nil {
+		os.Remove(storagePath)
+		return nil, nil, err
 	}
 
 	project := model.Project{
@@ -51,17 +80,34 @@ func CreateProject(file *multipart.FileHeader, userID uint, projectName string)
 		UserID:           userID,
 		ArqPath:          storagePath,
 		OriginalFilename: file.Filename,
+		SourceType:       sourceType,
 		ConfigLine:       1,
 		ConfigColumn:     "A",
 	}
+	if len(sheets) == 1 {
+		project.ConfigSheet = sheets[0]
+	}
+	if detected.Confidence > 0 {
+		project.ConfigSheet = detected.Sheet
+		project.ConfigLine = detected.Line
+		project.ConfigColumn = detected.Column
+		project.ConfigDateColumn = detected.DateColumn
+	}
 
 	result := initializers.DB.Create(&project)
 	if result.Error != nil {
 		os.Remove(storagePath)
-		return nil, result.Error
+		return nil, nil, result.Error
 	}
 
-	return &project, nil
+	if err := importProjectTransactions(&project); err != nil {
+		// The import rolled back its own writes; drop the project with it.
+		initializers.DB.Unscoped().Delete(&project)
+		os.Remove(storagePath)
+		return nil, nil, err
+	}
+
+	return &project, detected, nil
 }
 
 func GetProjectsForUser(userID uint) ([]model.Project, error) {
@@ -73,41 +119,86 @@ func GetProjectsForUser(userID uint) ([]model.Project, error) {
 	return projects, nil
 }
 
-func UpdateProjectSettings(userID, projectID uint, sheet, column, dateColumn string, line int) (*model.Project, error) {
+// ProjectSettings tells the importer where the data lives in the project file.
+// Sheet and Line are always required, plus Column in the signed amount mode
+// or InflowColumn and OutflowColumn in the split mode. An empty NumberLocale
+// detects the number format from the file, an empty DateFormat the layout of
+// the date column. OpeningBalanceDate is optional; OpeningBalanceCell, when
+// set, takes precedence over OpeningBalance.
+type ProjectSettings struct {
+	Sheet             string
+	Column            string
+	DateColumn        string
+	CategoryColumn    string
+	DescriptionColumn string
+	AmountMode        string
+	InflowColumn      string
+	OutflowColumn     string
+	NumberLocale      string
+	DateFormat        string
+	Line              int
+
+	OpeningBalance     float64
+	OpeningBalanceDate string
+	OpeningBalanceCell string
+}
+
+func UpdateProjectSettings(userID, projectID uint, settings ProjectSettings) (*model.Project, error) {
 	var project model.Project
 
+	if settings.AmountMode == "" {
+		settings.AmountMode = model.AmountModeSigned
+	}
+
 	result := initializers.DB.First(&project, "id = ? AND user_id = ?", projectID, userID)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("Project not found or acess denied")
+			return nil, errProjectNotFound
 		}
 		return nil, result.Error
 	}
 
-	project.ConfigSheet = sheet
-	project.ConfigColumn = column
-	project.ConfigDateColumn = dateColumn
-	project.ConfigLine = line
+	project.ConfigSheet = settings.Sheet
+	project.ConfigColumn = settings.Column
+	project.ConfigDateColumn = settings.DateColumn
+	project.ConfigCategoryColumn = settings.CategoryColumn
+	project.ConfigDescriptionColumn = settings.DescriptionColumn
+	project.ConfigAmountMode = settings.AmountMode
+	project.ConfigInflowColumn = settings.InflowColumn
+	project.ConfigOutflowColumn = settings.OutflowColumn
+	project.ConfigNumberLocale = settings.NumberLocale
+	project.ConfigDateFormat = settings.DateFormat
+	project.ConfigLine = settings.Line
+	project.ConfigOpeningBalance = settings.OpeningBalance
+	project.ConfigOpeningBalanceCell = settings.OpeningBalanceCell
+	project.ConfigOpeningBalanceDate = nil
+	if settings.OpeningBalanceDate != "" {
+		date, err := parseDate(settings.OpeningBalanceDate)
+		if err != nil {
+			return nil, i18n.NewError(i18n.CodeInvalidOpeningDate, i18n.Params{"value": settings.OpeningBalanceDate})
+		}
+		project.ConfigOpeningBalanceDate = &date
+	}
 
 	saveResult := initializers.DB.Save(&project)
 	if saveResult.Error != nil {
 		return nil, saveResult.Error
 	}
 
+	if err := importProjectTransactions(&project); err != nil {
+		return nil, err
+	}
+
 	return &project, nil
 }
 
-func UpdateProjectFile(userID, projectID uint, file *multipart.FileHeader) (*model.Project, error) {
+func UpdateProjectFile(userID, projectID uint, file *multipart.FileHeader, sourceType string) (*model.Project, error) {
     var project model.Project
 
     
     result := initializers.DB.First(&project, "id = ? AND user_id = ?", projectID, userID)
     if result.Error != nil {
-        return nil, fmt.Errorf("Projeto não encontrado ou acesso negado")
-    }
-
-    if project.ArqPath != "" {
-        _ = os.Remove(project.ArqPath) 
+        return nil, errProjectNotFound
     }
 
     src, err := file.Open()
@@ -134,127 +225,159 @@ func UpdateProjectFile(userID, projectID uint, file *multipart.FileHeader) (*mod
         return nil, err
     }
 
+    sourceType, sheets, _, err := inspectStoredFile(storagePath, sourceType)
+    if err != nil {
+        os.Remove(storagePath)
+        return nil, err
+    }
+
+    if project.ArqPath != "" && project.ArqPath != storagePath {
+        _ = os.Remove(project.ArqPath) 
+    }
+
     project.ArqPath = storagePath
     project.OriginalFilename = file.Filename
-    
+    project.SourceType = sourceType
+    if len(sheets) == 1 {
+        project.ConfigSheet = sheets[0]
+    }
 
     if err := initializers.DB.Save(&project).Error; err != nil {
         return nil, err
     }
 
+    if err := importProjectTransactions(&project); err != nil {
+        return nil, err
+    }
+
     return &project, nil
 }
 
-func GetProjectAnalysis(userID, projectID uint, analysisType string) (*analysis.AnalysisResult, error) {
+// AnalysisOptions shape the analysis output. The zero value analyses every
+// transaction and returns one point per transaction. Simulation only applies
+// to the monte_carlo analysis type and Anomaly to the anomalies one.
+type AnalysisOptions struct {
+	Granularity      analysis.Granularity
+	Range            DateRange
+	BurnWindowMonths int
+	Language         string
+	Simulation       analysis.SimulationOptions
+	Anomaly          analysis.AnomalyOptions
+}
+
+// loadProjectSeries returns a project of the user with its transactions as a
+// series, sorted by date when the project is dated, or the error its last
+// import failed with.
+func loadProjectSeries(userID, projectID uint) (*model.Project, []analysis.TimeSeriesDataPoint, error) {
 	var project model.Project
 
 	result := initializers.DB.First(&project, "id = ? AND user_id = ?", projectID, userID)
 	if result.Error != nil {
-		return nil, fmt.Errorf("Project not found or acess denied")
+		return nil, nil, errProjectNotFound
 	}
 
-	if project.ConfigSheet == "" || project.ConfigColumn == "" || project.ConfigLine <= 0 {
-		return nil, fmt.Errorf("Project not configured. Please select sheet, column, and row")
+	// Projects uploaded before transactions were persisted are imported on
+	// their first analysis.
+	if project.ImportedAt == nil {
+		if err := importProjectTransactions(&project); err != nil {
+			return nil, nil, err
+		}
 	}
-
-	f, err := excelize.OpenFile(project.ArqPath)
-	if err != nil {
-		return nil, fmt.Errorf("Failed to open %v", err)
+	if project.ImportErrorCode != "" {
+		return nil, nil, i18n.NewError(project.ImportErrorCode, project.ImportErrorParams)
+	}
+	if project.ImportError != "" {
+		// Stored before import errors had codes.
+		return nil, nil, i18n.NewError(i18n.CodeInvalidFile, i18n.Params{"detail": project.ImportError})
 	}
-	defer f.Close()
 
-	rows, err := f.GetRows(project.ConfigSheet)
+	transactions, err := loadProjectTransactions(project)
 	if err != nil {
-		return nil, fmt.Errorf("A aba '%s' não foi encontrada no arquivo Excel. Verifique o nome.", project.ConfigSheet)
+		return nil, nil, err
 	}
+	return &project, transactionsToSeries(transactions), nil
+}
 
-	valueColIndex := -1
-	dateColIndex := -1
-	if project.ConfigLine > 0 && project.ConfigLine-1 < len(rows) {
-		headerRow := rows[project.ConfigLine-1]
-		for i, colName := range headerRow {
-			if colName == project.ConfigColumn {
-				valueColIndex = i
-			}
-			if project.ConfigDateColumn != "" && colName == project.ConfigDateColumn {
-				dateColIndex = i
-			}
-		}
-	}
+// datedAnalysisTypes need dated transactions; the other types fall back to
+// the basic analysis of the amounts.
+var datedAnalysisTypes = []string{
+	analysis.AnalysisMonteCarlo,
+	analysis.AnalysisBudgetVariance,
+	analysis.AnalysisAnomalies,
+	analysis.AnalysisRecurring,
+	analysis.AnalysisComparison,
+}
 
-	if valueColIndex == -1 {
-		return nil, fmt.Errorf("A coluna de valor '%s' não foi encontrada na linha %d.", project.ConfigColumn, project.ConfigLine)
+func GetProjectAnalysis(userID, projectID uint, analysisType string, options AnalysisOptions) (*analysis.AnalysisResult, error) {
+	project, series, err := loadProjectSeries(userID, projectID)
+	if err != nil {
+		return nil, err
 	}
-
-	if dateColIndex != -1 {
-		var series []analysis.TimeSeriesDataPoint
-		for i := project.ConfigLine; i < len(rows); i++ {
-			row := rows[i]
-			if valueColIndex < len(row) && dateColIndex < len(row) {
-				valStr := row[valueColIndex]
-				dateStr := row[dateColIndex]
-
-				val, err := parseBrazilianNumber(valStr) 
-                if err != nil {
-                     val, err = strconv.ParseFloat(valStr, 64)
-                     if err != nil {
-                        continue 
-                     }
-                }
-
-				date, err := parseDate(dateStr)
-				if err != nil {
-					continue 
-				}
-
-				series = append(series, analysis.TimeSeriesDataPoint{Date: date, Value: val})
-			}
-		}
-		return analysis.CalculateTimeSeriesAnalysis(series, analysisType, project.ConfigColumn), nil
+	if !project.ImportDated && slices.Contains(datedAnalysisTypes, analysisType) {
+		return nil, i18n.NewError(i18n.CodeAnalysisRequiresDates, i18n.Params{"type": analysisType})
 	}
 
-	var data []float64
-	for i := project.ConfigLine; i < len(rows); i++ {
-		row := rows[i]
-		if valueColIndex < len(row) {
-			valStr := row[valueColIndex]
-			if val, err := strconv.ParseFloat(valStr, 64); err == nil {
-				data = append(data, val)
+	var analysisResult *analysis.AnalysisResult
+	if project.ImportDated {
+		// Points before the range still count towards the balance it opens with.
+		filtered, balanceBefore := options.Range.filterSeries(series)
+		openingBalance := projectOpeningBalance(*project, series) + balanceBefore
+		healthPolicy, err := effectiveHealthPolicy(userID, &project.ID)
+		if err != nil {
+			return nil, err
+		}
+		var simulation *analysis.SimulationOptions
+		if analysisType == analysis.AnalysisMonteCarlo {
+			simulation = &options.Simulation
+		}
+		analysisResult = analysis.CalculateTimeSeriesAnalysis(filtered, analysisType, project.ImportColumn, analysis.TimeSeriesOptions{
+			OpeningBalance:   openingBalance,
+			BurnWindowMonths: options.BurnWindowMonths,
+			HealthPolicy:     &healthPolicy.Policy,
+			Language:         options.Language,
+			Simulation:       simulation,
+		})
+		analysisResult.From = formatRangeBound(options.Range.From)
+		analysisResult.To = formatRangeBound(options.Range.To)
+		if analysisType == analysis.AnalysisBudgetVariance {
+			budget, err := loadBudgetEntries(project.ID, options.Range)
+			if err != nil {
+				return nil, err
 			}
+			analysisResult.BudgetVariance = analysis.CalculateBudgetVariance(analysisResult.Series, budget, options.Language)
+		}
+		if analysisType == analysis.AnalysisAnomalies {
+			analysisResult.Anomalies = analysis.DetectAnomalies(analysisResult.Series, options.Anomaly)
 		}
+		if analysisType == analysis.AnalysisComparison {
+			analysisResult.Comparison = analysis.CalculateComparison(analysisResult.Series)
+		}
+		analysis.AggregateByPeriod(analysisResult, options.Granularity)
+	} else {
+		values := make([]float64, len(series))
+		for i, point := range series {
+			values[i] = point.Value
+		}
+		analysisResult = analysis.CalculateBasicAnalysis(values, analysisType, project.ImportColumn)
 	}
+	analysisResult.ParseSummary = project.ImportSummary
 
-	analysisResult := analysis.CalculateBasicAnalysis(data, analysisType, project.ConfigColumn)
 	return analysisResult, nil
 }
 
-func parseDate(dateStr string) (time.Time, error) {
-	layouts := []string{
-		"2006-01-02",
-		"02/01/2006",
-		"01-02-2006",
-		"02-Jan-2006",
-		time.RFC3339,
+func loadRows(reader source.SourceReader, sheet string) ([][]string, error) {
+	var rows [][]string
+	err := reader.Rows(sheet, func(line int, row []string) bool {
+		rows = append(rows, row)
+		return true
+	})
+	if errors.Is(err, source.ErrSheetNotFound) {
+		return nil, sheetNotFoundError(sheet)
 	}
-	for _, layout := range layouts {
-		t, err := time.Parse(layout, dateStr)
-		if err == nil {
-			return t, nil
-		}
+	if err != nil {
+		return nil, sourceError(err, i18n.CodeFileOpenFailed)
 	}
-	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
-}
-
-
-func parseBrazilianNumber(valStr string) (float64, error) {
-    cleanStr := strings.ReplaceAll(valStr, "R$", "")
-    cleanStr = strings.ReplaceAll(cleanStr, " ", "")
-    cleanStr = strings.ReplaceAll(cleanStr, ".", "") 
-
-    
-    cleanStr = strings.ReplaceAll(cleanStr, ",", ".")
-
-    return strconv.ParseFloat(cleanStr, 64)
+	return rows, nil
 }
 
 func DeleteProject(userID, projectID uint) error {
@@ -262,12 +385,25 @@ func DeleteProject(userID, projectID uint) error {
 
     result := initializers.DB.First(&project, "id = ? AND user_id = ?", projectID, userID)
     if result.Error != nil {
-        return result.Error 
+        return errProjectNotFound
     }
 
     if project.ArqPath != "" {
         os.Remove(project.ArqPath)
     }
 
+    if err := initializers.DB.Unscoped().Where("project_id = ?", project.ID).Delete(&model.Transaction{}).Error; err != nil {
+        return err
+    }
+    if err := deleteHealthPolicy(userID, &project.ID); err != nil {
+        return err
+    }
+    if err := initializers.DB.Unscoped().Where("project_id = ?", project.ID).Delete(&model.Scenario{}).Error; err != nil {
+        return err
+    }
+    if err := initializers.DB.Unscoped().Where("project_id = ?", project.ID).Delete(&model.BudgetLine{}).Error; err != nil {
+        return err
+    }
+
     return initializers.DB.Unscoped().Delete(&project).Error
 }
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"finview/backend/internal/analysis"
+	"finview/backend/internal/i18n"
+	"finview/backend/internal/projects/model"
+	"finview/backend/internal/projects/source"
+	"math"
+	"strings"
+)
+
+// Reasons a row is left out of the analysis.
+const (
+	SkipReasonShortRow   = "short_row"
+	SkipReasonBadDate    = "unparseable_date"
+	SkipReasonBadNumber  = "unparseable_number"
+	SkipReasonNoAmount   = "missing_amount"
+	maxListedSkippedRows = 1000
+)
+
+type SkippedRow struct {
+	Line   int      `json:"line"`
+	Cells  []string `json:"cells"`
+	Reason string   `json:"reason"`
+}
+
+// ParseDiagnostics lists the rows that did not make it into the analysis. The
+// list is capped, the summary always counts every row.
+type ParseDiagnostics struct {
+	Summary   analysis.ParseSummary `json:"summary"`
+	Skipped   []SkippedRow          `json:"skipped"`
+	Truncated bool                  `json:"truncated"`
+}
+
+type parsedRow struct {
+	Line  int
+	Point analysis.TimeSeriesDataPoint
+}
+
+// projectData is the project file reduced to the rows the analysis works on.
+// Dated is false when no date column is configured; the points then only
+// carry values. OpeningBalance is set when it is read from a cell.
+type projectData struct {
+	Column         string
+	Dated          bool
+	Rows           []parsedRow
+	Diagnostics    ParseDiagnostics
+	OpeningBalance *float64
+}
+
+func (d *ParseDiagnostics) skip(line int, cells []string, reason string) {
+	d.Summary.SkippedRows++
+	d.Summary.SkippedByReason[reason]++
+	if len(d.Skipped) >= maxListedSkippedRows {
+		d.Truncated = true
+		return
+	}
+	d.Skipped = append(d.Skipped, SkippedRow{Line: line, Cells: cells, Reason: reason})
+}
+
+func newParseDiagnostics() ParseDiagnostics {
+	return ParseDiagnostics{
+		Summary: analysis.ParseSummary{SkippedByReason: map[string]int{}},
+		Skipped: []SkippedRow{},
+	}
+}
+
+// loadProjectData opens the project file and parses it with the project
+// settings.
+func loadProjectData(project model.Project) (*projectData, error) {
+	reader, _, err := source.Open(project.ArqPath)
+	if err != nil {
+		return nil, sourceError(err, i18n.CodeFileOpenFailed)
+	}
+	defer reader.Close()
+
+	// Sources such as OFX carry their own date and amount fields, so they need
+	// no column configuration.
+	if seriesReader, ok := reader.(source.SeriesReader); ok {
+		series, err := seriesReader.Series()
+		if err != nil {
+			return nil, sourceError(err, i18n.CodeFileOpenFailed)
+		}
+
+		data := &projectData{Column: "TRNAMT", Dated: true, Diagnostics: newParseDiagnostics()}
+		for i, point := range series {
+			data.Rows = append(data.Rows, parsedRow{Line: i + 1, Point: point})
+		}
+		data.Diagnostics.Summary.ParsedRows = len(series)
+		return data, nil
+	}
+
+	if !isProjectConfigured(project) {
+		return nil, i18n.NewError(i18n.CodeProjectNotConfigured, nil)
+	}
+
+	rows, err := loadRows(reader, project.ConfigSheet)
+	if err != nil {
+		return nil, err
+	}
+
+	locale := numberLocaleFor(project.ConfigNumberLocale, reader, rows)
+	data, err := parseProjectRows(project, rows, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	if project.ConfigOpeningBalanceCell != "" {
+		openingBalance, err := readOpeningBalanceCell(reader, project, locale)
+		if err != nil {
+			return nil, err
+		}
+		data.OpeningBalance = &openingBalance
+	}
+	return data, nil
+}
+
+func parseProjectRows(project model.Project, rows [][]string, locale NumberLocale) (*projectData, error) {
+	var headerRow []string
+	if project.ConfigLine > 0 && project.ConfigLine-1 < len(rows) {
+		headerRow = rows[project.ConfigLine-1]
+	}
+
+	split := project.ConfigAmountMode == model.AmountModeSplit
+	valueColIndex := columnIndex(headerRow, project.ConfigColumn)
+	inflowColIndex := columnIndex(headerRow, project.ConfigInflowColumn)
+	outflowColIndex := columnIndex(headerRow, project.ConfigOutflowColumn)
+	dateColIndex := columnIndex(headerRow, project.ConfigDateColumn)
+	categoryColIndex := columnIndex(headerRow, project.ConfigCategoryColumn)
+	descriptionColIndex := columnIndex(headerRow, project.ConfigDescriptionColumn)
+
+	if split {
+		if inflowColIndex == -1 {
+			return nil, columnNotFoundError(i18n.CodeInflowColumnNotFound, project.ConfigInflowColumn, project.ConfigLine)
+		}
+		if outflowColIndex == -1 {
+			return nil, columnNotFoundError(i18n.CodeOutflowColumnNotFound, project.ConfigOutflowColumn, project.ConfigLine)
+		}
+	} else if valueColIndex == -1 {
+		return nil, columnNotFoundError(i18n.CodeValueColumnNotFound, project.ConfigColumn, project.ConfigLine)
+	}
+	if project.ConfigCategoryColumn != "" && categoryColIndex == -1 {
+		return nil, columnNotFoundError(i18n.CodeCategoryColumnNotFound, project.ConfigCategoryColumn, project.ConfigLine)
+	}
+	if project.ConfigDescriptionColumn != "" && descriptionColIndex == -1 {
+		return nil, columnNotFoundError(i18n.CodeDescriptionColumnNotFound, project.ConfigDescriptionColumn, project.ConfigLine)
+	}
+
+	data := &projectData{
+		Column:      project.ConfigColumn,
+		Dated:       dateColIndex != -1,
+		Diagnostics: newParseDiagnostics(),
+	}
+	if split {
+		data.Column = project.ConfigInflowColumn + "/" + project.ConfigOutflowColumn
+	}
+
+	parseValue := func(valStr string) (float64, error) {
+		return parseNumber(valStr, locale)
+	}
+
+	for i := project.ConfigLine; i < len(rows); i++ {
+		row := rows[i]
+		line := i + 1
+		if isBlankRow(row) {
+			continue
+		}
+
+		if (!split && valueColIndex >= len(row)) || (data.Dated && dateColIndex >= len(row)) {
+			data.Diagnostics.skip(line, row, SkipReasonShortRow)
+			continue
+		}
+
+		var val float64
+		var reason string
+		if split {
+			val, reason = splitAmount(cellAt(row, inflowColIndex), cellAt(row, outflowColIndex), parseValue)
+		} else if v, err := parseValue(row[valueColIndex]); err != nil {
+			reason = SkipReasonBadNumber
+		} else {
+			val = v
+		}
+		if reason != "" {
+			data.Diagnostics.skip(line, row, reason)
+			continue
+		}
+
+		point := analysis.TimeSeriesDataPoint{
+			Value:       val,
+			Category:    cellAt(row, categoryColIndex),
+			Description: cellAt(row, descriptionColIndex),
+		}
+		if data.Dated {
+			date, err := parseDateWithFormat(row[dateColIndex], project.ConfigDateFormat)
+			if err != nil {
+				data.Diagnostics.skip(line, row, SkipReasonBadDate)
+				continue
+			}
+			point.Date = date
+		}
+
+		data.Rows = append(data.Rows, parsedRow{Line: line, Point: point})
+	}
+
+	data.Diagnostics.Summary.ParsedRows = len(data.Rows)
+	return data, nil
+}
+
+// splitAmount combines an inflow and an outflow cell into a signed value.
+// Both columns hold magnitudes, whatever sign the export used; zero counts
+// as empty. When both are filled the row is their net, when neither is the
+// row is skipped.
+func splitAmount(inflowStr, outflowStr string, parseValue func(string) (float64, error)) (float64, string) {
+	var inflow, outflow float64
+	if inflowStr != "" {
+		v, err := parseValue(inflowStr)
+		if err != nil {
+			return 0, SkipReasonBadNumber
+		}
+		inflow = math.Abs(v)
+	}
+	if outflowStr != "" {
+		v, err := parseValue(outflowStr)
+		if err != nil {
+			return 0, SkipReasonBadNumber
+		}
+		outflow = math.Abs(v)
+	}
+
+	if inflow == 0 && outflow == 0 {
+		return 0, SkipReasonNoAmount
+	}
+	return inflow - outflow, ""
+}
+
+// columnIndex finds a header by its exact name; an empty name is never found.
+func columnIndex(headerRow []string, name string) int {
+	if name == "" {
+		return -1
+	}
+	for i, colName := range headerRow {
+		if colName == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// cellAt returns the trimmed cell or "" when the column is not mapped or the
+// row is shorter than it.
+func cellAt(row []string, index int) string {
+	if index < 0 || index >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[index])
+}
+
+func isProjectConfigured(project model.Project) bool {
+	if project.ConfigSheet == "" || project.ConfigLine <= 0 {
+		return false
+	}
+	if project.ConfigAmountMode == model.AmountModeSplit {
+		return project.ConfigInflowColumn != "" && project.ConfigOutflowColumn != ""
+	}
+	return project.ConfigColumn != ""
+}
+
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"finview/backend/initializers"
+	"finview/backend/internal/analysis"
+	"finview/backend/internal/i18n"
+	"finview/backend/internal/projects/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScenarioInput is a scenario as sent by the client. Adjustment dates accept
+// the same formats as the spreadsheet date columns.
+type ScenarioInput struct {
+	Name        string
+	Description string
+	Adjustments []AdjustmentInput
+}
+
+type AdjustmentInput struct {
+	Type        string
+	Description string
+	Amount      float64
+	Date        string
+	Start       string
+	End         string
+	Category    string
+	Percent     float64
+}
+
+// ScenarioCompareOptions shape a scenario comparison. A zero HorizonMonths
+// compares the history only.
+type ScenarioCompareOptions struct {
+	HorizonMonths    int
+	BurnWindowMonths int
+	Language         string
+}
+
+func ListScenarios(userID, projectID uint) ([]model.Scenario, error) {
+	project, err := findUserProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var scenarios []model.Scenario
+	result := initializers.DB.Where("project_id = ?", project.ID).Order("id").Find(&scenarios)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return scenarios, nil
+}
+
+func GetScenario(userID, projectID, scenarioID uint) (*model.Scenario, error) {
+	return findScenario(userID, projectID, scenarioID)
+}
+
+func CreateScenario(userID, projectID uint, input ScenarioInput) (*model.Scenario, error) {
+	project, err := findUserProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	scenario := model.Scenario{ProjectID: project.ID}
+	if err := applyScenarioInput(&scenario, input); err != nil {
+		return nil, err
+	}
+
+	if err := initializers.DB.Create(&scenario).Error; err != nil {
+		return nil, err
+	}
+	return &scenario, nil
+}
+
+func UpdateScenario(userID, projectID, scenarioID uint, input ScenarioInput) (*model.Scenario, error) {
+	scenario, err := findScenario(userID, projectID, scenarioID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyScenarioInput(scenario, input); err != nil {
+		return nil, err
+	}
+
+	if err := initializers.DB.Save(scenario).Error; err != nil {
+		return nil, err
+	}
+	return scenario, nil
+}
+
+func DeleteScenario(userID, projectID, scenarioID uint) error {
+	scenario, err := findScenario(userID, projectID, scenarioID)
+	if err != nil {
+		return err
+	}
+
+	return initializers.DB.Unscoped().Delete(scenario).Error
+}
+
+// CompareScenario runs the scenario and the project as it is side by side,
+// from the project's whole history.
+func CompareScenario(userID, projectID, scenarioID uint, options ScenarioCompareOptions) (*analysis.ScenarioComparison, error) {
+	scenario, err := findScenario(userID, projectID, scenarioID)
+	if err != nil {
+		return nil, err
+	}
+
+	project, series, err := loadProjectSeries(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if !project.ImportDated {
+		return nil, i18n.NewError(i18n.CodeScenarioRequiresDates, nil)
+	}
+
+	healthPolicy, err := effectiveHealthPolicy(userID, &project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return analysis.CompareScenario(series, scenario.Adjustments, analysis.ScenarioOptions{
+		OpeningBalance:   projectOpeningBalance(*project, series),
+		HorizonMonths:    options.HorizonMonths,
+		BurnWindowMonths: options.BurnWindowMonths,
+		HealthPolicy:     &healthPolicy.Policy,
+		Language:         options.Language,
+	}), nil
+}
+
+func findScenario(userID, projectID, scenarioID uint) (*model.Scenario, error) {
+	project, err := findUserProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var scenario model.Scenario
+	result := initializers.DB.First(&scenario, "id = ? AND project_id = ?", scenarioID, project.ID)
+	if result.Error != nil {
+		return nil, i18n.NewError(i18n.CodeScenarioNotFound, nil)
+	}
+	return &scenario, nil
+}
+
+func applyScenarioInput(scenario *model.Scenario, input ScenarioInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return i18n.NewError(i18n.CodeScenarioNameRequired, nil)
+	}
+
+	adjustments := make([]analysis.ScenarioAdjustment, len(input.Adjustments))
+	for i, in := range input.Adjustments {
+		adjustment := analysis.ScenarioAdjustment{
+			Type:        in.Type,
+			Description: in.Description,
+			Amount:      in.Amount,
+			Category:    strings.TrimSpace(in.Category),
+			Percent:     in.Percent,
+		}
+		var err error
+		if adjustment.Date, err = parseAdjustmentDate(i, in.Date); err != nil {
+			return err
+		}
+		if adjustment.Start, err = parseAdjustmentDate(i, in.Start); err != nil {
+			return err
+		}
+		if adjustment.End, err = parseAdjustmentDate(i, in.End); err != nil {
+			return err
+		}
+		if err := adjustment.Validate(i); err != nil {
+			return err
+		}
+		adjustments[i] = adjustment
+	}
+
+	scenario.Name = name
+	scenario.Description = input.Description
+	scenario.Adjustments = adjustments
+	return nil
+}
+
+func parseAdjustmentDate(index int, value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	date, err := parseDate(value)
+	if err != nil {
+		return nil, i18n.NewError(i18n.CodeAdjustmentInvalidDate, i18n.Params{
+			"adjustment": strconv.Itoa(index + 1),
+			"value":      value,
+		})
+	}
+	return &date, nil
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"finview/backend/initializers"
+	"finview/backend/internal/i18n"
+	"finview/backend/internal/projects/model"
+	"finview/backend/internal/projects/source"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	headerScanLines     = 30
+	maxHeaderCandidates = 3
+	columnScanRows      = 500
+	columnSampleSize    = 5
+)
+
+// Inferred column types.
+const (
+	ColumnTypeDate     = "date"
+	ColumnTypeNumber   = "number"
+	ColumnTypeCurrency = "currency"
+	ColumnTypeText     = "text"
+	ColumnTypeEmpty    = "empty"
+)
+
+type ProjectSchema struct {
+	ProjectID  uint          `json:"project_id"`
+	SourceType string        `json:"source_type"`
+	Sheets     []SheetSchema `json:"sheets"`
+}
+
+type SheetSchema struct {
+	Name             string            `json:"name"`
+	UsedRange        string            `json:"used_range"`
+	RowCount         int               `json:"row_count"`
+	ColumnCount      int               `json:"column_count"`
+	HeaderCandidates []HeaderCandidate `json:"header_candidates"`
+	Columns          []ColumnSchema    `json:"columns"`
+}
+
+type HeaderCandidate struct {
+	Line    int      `json:"line"`
+	Score   float64  `json:"score"`
+	Headers []string `json:"headers"`
+}
+
+type ColumnSchema struct {
+	Index        int      `json:"index"`
+	Letter       string   `json:"letter"`
+	Header       string   `json:"header"`
+	InferredType string   `json:"inferred_type"`
+	Samples      []string `json:"samples"`
+}
+
+// GetProjectSchema describes every sheet of the project file so the settings
+// form can offer the sheet, header line and columns instead of free text.
+// Columns are inferred below the best header candidate.
+func GetProjectSchema(userID, projectID uint) (*ProjectSchema, error) {
+	var project model.Project
+
+	result := initializers.DB.First(&project, "id = ? AND user_id = ?", projectID, userID)
+	if result.Error != nil {
+		return nil, errProjectNotFound
+	}
+
+	reader, fileType, err := source.Open(project.ArqPath)
+	if err != nil {
+		return nil, sourceError(err, i18n.CodeFileOpenFailed)
+	}
+	defer reader.Close()
+
+	schema := &ProjectSchema{ProjectID: project.ID, SourceType: fileType}
+
+	for _, sheet := range reader.Sheets() {
+		rows, err := loadRows(reader, sheet)
+		if err != nil {
+			return nil, err
+		}
+		locale := numberLocaleFor(project.ConfigNumberLocale, reader, rows)
+		schema.Sheets = append(schema.Sheets, describeSheet(sheet, rows, locale))
+	}
+
+	return schema, nil
+}
+
+func describeSheet(name string, rows [][]string, locale NumberLocale) SheetSchema {
+	sheet := SheetSchema{Name: name}
+
+	firstRow, lastRow, lastCol := 0, 0, 0
+	for i, row := range rows {
+		filled := false
+		for j, cell := range row {
+			if strings.TrimSpace(cell) != "" {
+				filled = true
+				if j+1 > lastCol {
+					lastCol = j + 1
+				}
+			}
+		}
+		if filled {
+			if firstRow == 0 {
+				firstRow = i + 1
+			}
+			lastRow = i + 1
+		}
+	}
+	if lastRow == 0 {
+		return sheet
+	}
+
+	sheet.RowCount = lastRow - firstRow + 1
+	sheet.ColumnCount = lastCol
+	topLeft, _ := excelize.CoordinatesToCellName(1, firstRow)
+	bottomRight, _ := excelize.CoordinatesToCellName(lastCol, lastRow)
+	sheet.UsedRange = topLeft + ":" + bottomRight
+
+	sheet.HeaderCandidates = detectHeaderCandidates(rows, locale)
+	headerLine := firstRow
+	if len(sheet.HeaderCandidates) > 0 {
+		headerLine = sheet.HeaderCandidates[0].Line
+	}
+	sheet.Columns = inferColumns(rows, headerLine, lastCol, locale)
+
+	return sheet
+}
+
+// detectHeaderCandidates scores the first lines of a sheet: a header is mostly
+// text, fills several cells, and is followed by typed (date/number) values.
+func detectHeaderCandidates(rows [][]string, locale NumberLocale) []HeaderCandidate {
+	var candidates []HeaderCandidate
+
+	for i := 0; i < len(rows) && i < headerScanLines; i++ {
+		row := rows[i]
+		filled, textual := 0, 0
+		for _, cell := range row {
+			cellType := classifyCell(cell, locale)
+			if cellType == ColumnTypeEmpty {
+				continue
+			}
+			filled++
+			if cellType == ColumnTypeText {
+				textual++
+			}
+		}
+		if filled < 2 || textual*2 < filled {
+			continue
+		}
+
+		typedBelow, filledBelow := 0, 0
+		for k := i + 1; k < len(rows) && k <= i+10; k++ {
+			for j := range row {
+				if j >= len(rows[k]) {
+					continue
+				}
+				cellType := classifyCell(rows[k][j], locale)
+				if cellType == ColumnTypeEmpty {
+					continue
+				}
+				filledBelow++
+				if cellType != ColumnTypeText {
+					typedBelow++
+				}
+			}
+		}
+		if filledBelow == 0 {
+			continue
+		}
+
+		textRatio := float64(textual) / float64(filled)
+		typedRatio := float64(typedBelow) / float64(filledBelow)
+		fillRatio := float64(filled) / float64(len(row))
+		score := textRatio*0.4 + typedRatio*0.4 + fillRatio*0.2
+
+		candidates = append(candidates, HeaderCandidate{
+			Line:    i + 1,
+			Score:   roundScore(score),
+			Headers: row,
+		})
+	}
+
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return candidates[a].Score > candidates[b].Score
+	})
+	if len(candidates) > maxHeaderCandidates {
+		candidates = candidates[:maxHeaderCandidates]
+	}
+	return candidates
+}
+
+// inferColumns assigns each column the type most of its non-empty cells
+// below the header line parse as.
+func inferColumns(rows [][]string, headerLine, columnCount int, locale NumberLocale) []ColumnSchema {
+	var header []string
+	if headerLine > 0 && headerLine-1 < len(rows) {
+		header = rows[headerLine-1]
+	}
+
+	columns := make([]ColumnSchema, columnCount)
+	votes := make([]map[string]int, columnCount)
+	for j := range columns {
+		letter, _ := excelize.ColumnNumberToName(j + 1)
+		columns[j] = ColumnSchema{Index: j, Letter: letter, Samples: []string{}}
+		if j < len(header) {
+			columns[j].Header = header[j]
+		}
+		votes[j] = map[string]int{}
+	}
+
+	for i := headerLine; i < len(rows) && i < headerLine+columnScanRows; i++ {
+		for j, cell := range rows[i] {
+			if j >= columnCount {
+				break
+			}
+			cellType := classifyCell(cell, locale)
+			if cellType == ColumnTypeEmpty {
+				continue
+			}
+			votes[j][cellType]++
+			if len(columns[j].Samples) < columnSampleSize {
+				columns[j].Samples = append(columns[j].Samples, cell)
+			}
+		}
+	}
+
+	for j := range columns {
+		columns[j].InferredType = dominantType(votes[j])
+	}
+	return columns
+}
+
+// dominantType treats currency as a kind of number: a column with any
+// currency-formatted cell and mostly numeric cells is reported as currency.
+func dominantType(votes map[string]int) string {
+	numeric := votes[ColumnTypeNumber] + votes[ColumnTypeCurrency]
+	best, bestCount := ColumnTypeEmpty, 0
+	for _, t := range []string{ColumnTypeDate, ColumnTypeText} {
+		if votes[t] > bestCount {
+			best, bestCount = t, votes[t]
+		}
+	}
+	if numeric > bestCount {
+		if votes[ColumnTypeCurrency] > 0 {
+			return ColumnTypeCurrency
+		}
+		return ColumnTypeNumber
+	}
+	return best
+}
+
+func classifyCell(cell string, locale NumberLocale) string {
+	value := strings.TrimSpace(cell)
+	if value == "" {
+		return ColumnTypeEmpty
+	}
+	if _, err := parseDate(value); err == nil {
+		return ColumnTypeDate
+	}
+	if _, err := parseNumber(value, locale); err == nil {
+		for _, marker := range currencyMarkers {
+			if strings.Contains(value, marker) {
+				return ColumnTypeCurrency
+			}
+		}
+		return ColumnTypeNumber
+	}
+	return ColumnTypeText
+}
+
+func roundScore(score float64) float64 {
+	return float64(int(score*100+0.5)) / 100
+}
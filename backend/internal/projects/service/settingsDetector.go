@@ -0,0 +1,128 @@
+package service
+
+import (
+	"finview/backend/internal/projects/source"
+	"strings"
+)
+
+const detectionScanRows = 200
+
+var (
+	dateHeaderHints   = []string{"data", "date", "dtposted", "vencimento", "dia"}
+	amountHeaderHints = []string{"valor", "montante", "quantia", "amount", "value", "trnamt", "total"}
+)
+
+// DetectedSettings is the detector's best guess for the project settings.
+// Confidence goes from 0 (nothing usable found) to 1.
+type DetectedSettings struct {
+	Sheet      string  `json:"sheet"`
+	Line       int     `json:"line"`
+	DateColumn string  `json:"date_column"`
+	Column     string  `json:"column"`
+	Confidence float64 `json:"confidence"`
+}
+
+// detectSettings tries every header candidate of every sheet and keeps the one
+// whose columns best parse as a date column and an amount column.
+func detectSettings(reader source.SourceReader) (*DetectedSettings, error) {
+	best := &DetectedSettings{}
+
+	for _, sheet := range reader.Sheets() {
+		rows, err := loadRows(reader, sheet)
+		if err != nil {
+			return nil, err
+		}
+		locale := numberLocaleFor("", reader, rows)
+
+		for _, candidate := range detectHeaderCandidates(rows, locale) {
+			detected := scoreHeaderCandidate(rows, candidate, locale)
+			if detected.Confidence > best.Confidence {
+				detected.Sheet = sheet
+				best = detected
+			}
+		}
+	}
+
+	return best, nil
+}
+
+func scoreHeaderCandidate(rows [][]string, candidate HeaderCandidate, locale NumberLocale) *DetectedSettings {
+	dateIdx, amountIdx := -1, -1
+	bestDateScore, bestAmountScore := 0.0, 0.0
+
+	for j, header := range candidate.Headers {
+		if strings.TrimSpace(header) == "" {
+			continue
+		}
+
+		dateRate, numberRate := columnParseRates(rows, candidate.Line, j, locale)
+
+		dateScore := dateRate + headerHintBonus(header, dateHeaderHints)
+		if dateRate > 0.5 && dateScore > bestDateScore {
+			dateIdx, bestDateScore = j, dateScore
+		}
+
+		amountScore := numberRate + headerHintBonus(header, amountHeaderHints)
+		if numberRate > 0.5 && dateRate < 0.5 && amountScore > bestAmountScore {
+			amountIdx, bestAmountScore = j, amountScore
+		}
+	}
+
+	detected := &DetectedSettings{Line: candidate.Line}
+	if amountIdx == -1 {
+		return detected
+	}
+	detected.Column = candidate.Headers[amountIdx]
+
+	confidence := candidate.Score*0.3 + clampScore(bestAmountScore)*0.35
+	if dateIdx != -1 {
+		detected.DateColumn = candidate.Headers[dateIdx]
+		confidence += clampScore(bestDateScore) * 0.35
+	}
+	detected.Confidence = roundScore(confidence)
+
+	return detected
+}
+
+// columnParseRates returns the share of non-empty cells below the header that
+// parse as a date and as a number.
+func columnParseRates(rows [][]string, headerLine, col int, locale NumberLocale) (float64, float64) {
+	filled, dates, numbers := 0, 0, 0
+	for i := headerLine; i < len(rows) && i < headerLine+detectionScanRows; i++ {
+		if col >= len(rows[i]) {
+			continue
+		}
+		cell := strings.TrimSpace(rows[i][col])
+		if cell == "" {
+			continue
+		}
+		filled++
+		if _, err := parseDate(cell); err == nil {
+			dates++
+		}
+		if _, err := parseNumber(cell, locale); err == nil {
+			numbers++
+		}
+	}
+	if filled == 0 {
+		return 0, 0
+	}
+	return float64(dates) / float64(filled), float64(numbers) / float64(filled)
+}
+
+func headerHintBonus(header string, hints []string) float64 {
+	lower := strings.ToLower(header)
+	for _, hint := range hints {
+		if strings.Contains(lower, hint) {
+			return 0.2
+		}
+	}
+	return 0
+}
+
+func clampScore(score float64) float64 {
+	if score > 1 {
+		return 1
+	}
+	return score
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"finview/backend/initializers"
+	"finview/backend/internal/i18n"
+	"finview/backend/internal/projects/model"
+	"strings"
+)
+
+const (
+	defaultTransactionPageSize = 50
+	maxTransactionPageSize     = 500
+)
+
+// TransactionInput is a manual entry as sent by the client. Date accepts the
+// same formats as the spreadsheet date columns.
+type TransactionInput struct {
+	Date        string
+	Amount      float64
+	Description string
+	Category    string
+}
+
+// TransactionFilter narrows the transaction listing. Empty or nil fields are
+// not applied.
+type TransactionFilter struct {
+	From      string
+	To        string
+	MinAmount *float64
+	MaxAmount *float64
+	Manual    *bool
+	Page      int
+	PageSize  int
+}
+
+type TransactionPage struct {
+	Transactions []model.Transaction `json:"transactions"`
+	Page         int                 `json:"page"`
+	PageSize     int                 `json:"page_size"`
+	Total        int64               `json:"total"`
+}
+
+func findUserProject(userID, projectID uint) (*model.Project, error) {
+	var project model.Project
+	result := initializers.DB.First(&project, "id = ? AND user_id = ?", projectID, userID)
+	if result.Error != nil {
+		return nil, errProjectNotFound
+	}
+	return &project, nil
+}
+
+func ListTransactions(userID, projectID uint, filter TransactionFilter) (*TransactionPage, error) {
+	project, err := findUserProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := initializers.DB.Model(&model.Transaction{}).Where("project_id = ?", project.ID)
+
+	if filter.From != "" {
+		from, err := parseDate(filter.From)
+		if err != nil {
+			return nil, i18n.NewError(i18n.CodeInvalidFromDate, i18n.Params{"value": filter.From})
+		}
+		query = query.Where("date >= ?", from)
+	}
+	if filter.To != "" {
+		to, err := parseDate(filter.To)
+		if err != nil {
+			return nil, i18n.NewError(i18n.CodeInvalidToDate, i18n.Params{"value": filter.To})
+		}
+		query = query.Where("date < ?", to.AddDate(0, 0, 1))
+	}
+	if filter.MinAmount != nil {
+		query = query.Where("amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("amount <= ?", *filter.MaxAmount)
+	}
+	if filter.Manual != nil {
+		query = query.Where("manual = ?", *filter.Manual)
+	}
+
+	page := TransactionPage{Page: filter.Page, PageSize: filter.PageSize}
+	if page.Page <= 0 {
+		page.Page = 1
+	}
+	if page.PageSize <= 0 {
+		page.PageSize = defaultTransactionPageSize
+	}
+	if page.PageSize > maxTransactionPageSize {
+		page.PageSize = maxTransactionPageSize
+	}
+
+	if err := query.Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+
+	result := query.Order("date, manual, source_row, id").
+		Offset((page.Page - 1) * page.PageSize).
+		Limit(page.PageSize).
+		Find(&page.Transactions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &page, nil
+}
+
+func CreateManualTransaction(userID, projectID uint, input TransactionInput) (*model.Transaction, error) {
+	project, err := findUserProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := model.Transaction{ProjectID: project.ID, Manual: true}
+	if err := applyTransactionInput(&transaction, input); err != nil {
+		return nil, err
+	}
+
+	if err := initializers.DB.Create(&transaction).Error; err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// UpdateManualTransaction only edits manual entries; file rows are changed by
+// re-uploading the file or changing the project settings.
+func UpdateManualTransaction(userID, projectID, transactionID uint, input TransactionInput) (*model.Transaction, error) {
+	transaction, err := findManualTransaction(userID, projectID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyTransactionInput(transaction, input); err != nil {
+		return nil, err
+	}
+
+	if err := initializers.DB.Save(transaction).Error; err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+func DeleteManualTransaction(userID, projectID, transactionID uint) error {
+	transaction, err := findManualTransaction(userID, projectID, transactionID)
+	if err != nil {
+		return err
+	}
+
+	return initializers.DB.Unscoped().Delete(transaction).Error
+}
+
+func findManualTransaction(userID, projectID, transactionID uint) (*model.Transaction, error) {
+	project, err := findUserProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var transaction model.Transaction
+	result := initializers.DB.First(&transaction, "id = ? AND project_id = ? AND manual = ?", transactionID, project.ID, true)
+	if result.Error != nil {
+		return nil, i18n.NewError(i18n.CodeTransactionNotFound, nil)
+	}
+	return &transaction, nil
+}
+
+func applyTransactionInput(transaction *model.Transaction, input TransactionInput) error {
+	date, err := parseDate(strings.TrimSpace(input.Date))
+	if err != nil {
+		return i18n.NewError(i18n.CodeInvalidDate, i18n.Params{"value": input.Date})
+	}
+
+	transaction.Date = date
+	transaction.Amount = input.Amount
+	transaction.Description = input.Description
+	transaction.Category = input.Category
+	return nil
+}
@@ -0,0 +1,277 @@
+package source
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const TypeCSV = "csv"
+
+// Delimited files have no tabs, so the whole file is exposed as a single sheet.
+const delimitedSheetName = "Planilha1"
+
+func init() {
+	Register(TypeCSV, func(path string) (SourceReader, error) {
+		return readDelimitedFile(path)
+	})
+}
+
+var delimiterCandidates = []rune{';', '\t', ',', '|'}
+
+type delimitedFile struct {
+	rows       [][]string
+	delimiter  rune
+	quote      rune
+	encoding   string
+	decimalSep rune
+}
+
+func (d *delimitedFile) Sheets() []string {
+	return []string{delimitedSheetName}
+}
+
+func (d *delimitedFile) Columns(sheet string, headerLine int) ([]string, error) {
+	return columnsFromRows(d, sheet, headerLine)
+}
+
+func (d *delimitedFile) Rows(sheet string, fn func(line int, row []string) bool) error {
+	if sheet != "" && sheet != delimitedSheetName {
+		return ErrSheetNotFound
+	}
+	for i, row := range d.rows {
+		if !fn(i+1, row) {
+			break
+		}
+	}
+	return nil
+}
+
+func (d *delimitedFile) DecimalSeparator() rune {
+	return d.decimalSep
+}
+
+func (d *delimitedFile) Close() error {
+	return nil
+}
+
+func readDelimitedFile(path string) (*delimitedFile, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	text, encoding := decodeText(raw)
+	if strings.TrimSpace(text) == "" {
+		return nil, ErrEmptyFile
+	}
+
+	quote := detectQuote(text)
+	delimiter := detectDelimiter(text, quote)
+	rows := parseDelimited(text, delimiter, quote)
+
+	return &delimitedFile{
+		rows:       rows,
+		delimiter:  delimiter,
+		quote:      quote,
+		encoding:   encoding,
+		decimalSep: DetectDecimalSeparator(rows),
+	}, nil
+}
+
+// decodeText returns the file content as UTF-8. Anything that is not valid
+// UTF-8 is treated as Windows-1252, which covers Latin-1 exports as well.
+func decodeText(raw []byte) (string, string) {
+	raw = bytes.TrimPrefix(raw, []byte("\xEF\xBB\xBF"))
+
+	encoding := "UTF-8"
+	if !utf8.Valid(raw) {
+		if decoded, err := charmap.Windows1252.NewDecoder().Bytes(raw); err == nil {
+			raw = decoded
+			encoding = "Windows-1252"
+		}
+	}
+
+	text := strings.ReplaceAll(string(raw), "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	return text, encoding
+}
+
+func sampleLines(text string, max int) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+		if len(lines) == max {
+			break
+		}
+	}
+	return lines
+}
+
+// detectQuote counts quote characters that open a field (line start or right
+// after a delimiter candidate) so apostrophes inside words are not counted.
+func detectQuote(text string) rune {
+	counts := map[rune]int{}
+	for _, line := range sampleLines(text, 50) {
+		prev := '\n'
+		for _, r := range line {
+			if (r == '"' || r == '\'') && (prev == '\n' || isDelimiterCandidate(prev)) {
+				counts[r]++
+			}
+			prev = r
+		}
+	}
+	if counts['\''] > counts['"'] {
+		return '\''
+	}
+	return '"'
+}
+
+func isDelimiterCandidate(r rune) bool {
+	for _, c := range delimiterCandidates {
+		if c == r {
+			return true
+		}
+	}
+	return false
+}
+
+// detectDelimiter picks the candidate that splits the sampled lines into the
+// most consistent number of fields (more than one).
+func detectDelimiter(text string, quote rune) rune {
+	lines := sampleLines(text, 20)
+
+	best := delimiterCandidates[0]
+	bestScore := 0
+	for _, candidate := range delimiterCandidates {
+		fieldCounts := map[int]int{}
+		for _, line := range lines {
+			fields := parseDelimited(line, candidate, quote)
+			if len(fields) > 0 {
+				fieldCounts[len(fields[0])]++
+			}
+		}
+
+		score := 0
+		for fields, occurrences := range fieldCounts {
+			if fields > 1 && occurrences*fields > score {
+				score = occurrences * fields
+			}
+		}
+		if score > bestScore {
+			best = candidate
+			bestScore = score
+		}
+	}
+	return best
+}
+
+// parseDelimited splits text into rows and fields, honouring quoted fields
+// that contain delimiters, line breaks or doubled quotes.
+func parseDelimited(text string, delimiter, quote rune) [][]string {
+	var rows [][]string
+	var row []string
+	var field strings.Builder
+	inQuotes := false
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if inQuotes {
+			if r == quote {
+				if i+1 < len(runes) && runes[i+1] == quote {
+					field.WriteRune(quote)
+					i++
+				} else {
+					inQuotes = false
+				}
+			} else {
+				field.WriteRune(r)
+			}
+			continue
+		}
+
+		switch {
+		case r == quote && strings.TrimSpace(field.String()) == "":
+			field.Reset()
+			inQuotes = true
+		case r == delimiter:
+			row = append(row, strings.TrimSpace(field.String()))
+			field.Reset()
+		case r == '\n':
+			row = append(row, strings.TrimSpace(field.String()))
+			field.Reset()
+			rows = append(rows, row)
+			row = nil
+		default:
+			field.WriteRune(r)
+		}
+	}
+
+	if field.Len() > 0 || len(row) > 0 {
+		row = append(row, strings.TrimSpace(field.String()))
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// DetectDecimalSeparator votes on every numeric-looking cell. When a cell has
+// both separators the last one is the decimal mark; a single separator
+// followed by exactly three digits is ambiguous and ignored. Ties go to the
+// Brazilian comma.
+func DetectDecimalSeparator(rows [][]string) rune {
+	votes := map[rune]int{}
+	for _, row := range rows {
+		for _, cell := range row {
+			if sep, ok := decimalSeparatorOf(cell); ok {
+				votes[sep]++
+			}
+		}
+	}
+	if votes['.'] > votes[','] {
+		return '.'
+	}
+	return ','
+}
+
+func decimalSeparatorOf(cell string) (rune, bool) {
+	clean := strings.NewReplacer("R$", "", "$", "", " ", "", "-", "", "+", "", "(", "", ")", "").Replace(cell)
+	if clean == "" || strings.Trim(clean, "0123456789.,") != "" || strings.Trim(clean, ".,") == "" {
+		return 0, false
+	}
+
+	lastComma := strings.LastIndex(clean, ",")
+	lastDot := strings.LastIndex(clean, ".")
+
+	switch {
+	case lastComma >= 0 && lastDot >= 0:
+		if lastComma > lastDot {
+			return ',', true
+		}
+		return '.', true
+	case lastComma >= 0:
+		return singleSeparatorVote(clean, ',', '.')
+	case lastDot >= 0:
+		return singleSeparatorVote(clean, '.', ',')
+	}
+	return 0, false
+}
+
+func singleSeparatorVote(clean string, sep, other rune) (rune, bool) {
+	if strings.Count(clean, string(sep)) > 1 {
+		return other, true
+	}
+	decimals := len(clean) - strings.LastIndex(clean, string(sep)) - 1
+	if decimals == 3 {
+		return 0, false
+	}
+	return sep, true
+}
@@ -0,0 +1,144 @@
+package source
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadDelimitedFile(t *testing.T) {
+	tests := []struct {
+		file      string
+		delimiter rune
+		encoding  string
+		decimal   rune
+		rows      [][]string
+	}{
+		{
+			file:      "testdata/semicolon_ptbr.csv",
+			delimiter: ';',
+			encoding:  "Windows-1252",
+			decimal:   ',',
+			rows: [][]string{
+				{"Data", "Descrição", "Valor", "Saldo"},
+				{"05/01/2024", "Aluguel; escritório", "-1.500,00", "8.500,00"},
+				{"10/01/2024", "Café da manhã", "-35,90", "8.464,10"},
+				{"15/01/2024", `Pagamento "ACME"`, "3.200,50", "11.664,60"},
+			},
+		},
+		{
+			file:      "testdata/comma_us.csv",
+			delimiter: ',',
+			encoding:  "UTF-8",
+			decimal:   '.',
+			rows: [][]string{
+				{"date", "description", "amount"},
+				{"2024-01-05", "Rent", "-1,500.00"},
+				{"2024-01-10", "Lunch, team", "-35.90"},
+				{"2024-01-15", "Invoice", "3200.50"},
+			},
+		},
+		{
+			file:      "testdata/tab.tsv",
+			delimiter: '\t',
+			encoding:  "UTF-8",
+			decimal:   '.',
+			rows: [][]string{
+				{"date", "description", "amount"},
+				{"2024-01-05", "Rent, office", "-1500"},
+				{"2024-01-10", "Lunch", "-35.9"},
+			},
+		},
+		{
+			file:      "testdata/pipe.txt",
+			delimiter: '|',
+			encoding:  "UTF-8",
+			decimal:   ',',
+			rows: [][]string{
+				{"date", "amount", "memo"},
+				{"2024-01-05", "-1500,00", "rent; office"},
+				{"2024-01-10", "-35,90", "lunch"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			file, err := readDelimitedFile(tt.file)
+			if err != nil {
+				t.Fatalf("readDelimitedFile() error = %v", err)
+			}
+			if file.delimiter != tt.delimiter {
+				t.Errorf("delimiter = %q, want %q", file.delimiter, tt.delimiter)
+			}
+			if file.encoding != tt.encoding {
+				t.Errorf("encoding = %s, want %s", file.encoding, tt.encoding)
+			}
+			if file.DecimalSeparator() != tt.decimal {
+				t.Errorf("decimal separator = %q, want %q", file.DecimalSeparator(), tt.decimal)
+			}
+			if !reflect.DeepEqual(file.rows, tt.rows) {
+				t.Errorf("rows = %q, want %q", file.rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestDetectDecimalSeparator(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]string
+		want rune
+	}{
+		{"comma decimals", [][]string{{"10,50"}, {"3,2"}}, ','},
+		{"dot decimals", [][]string{{"10.50"}, {"3.2"}}, '.'},
+		{"both separators, comma last", [][]string{{"1.234,56"}}, ','},
+		{"both separators, dot last", [][]string{{"1,234.56"}}, '.'},
+		{"repeated dots are thousands", [][]string{{"1.234.567"}}, ','},
+		{"repeated commas are thousands", [][]string{{"1,234,567"}}, '.'},
+		// Three digits after a single separator could be either.
+		{"ambiguous cells are ignored", [][]string{{"1.234"}, {"5.678"}, {"9,5"}}, ','},
+		{"currency and signs", [][]string{{"R$ -12.50"}, {"(3.25)"}, {"$ +1.5"}}, '.'},
+		{"text is ignored", [][]string{{"v1.2"}, {"12/01/2024"}, {"7,25"}}, ','},
+		{"ties go to the comma", [][]string{{"1.5"}, {"1,5"}}, ','},
+		{"no numbers", [][]string{{"abc"}}, ','},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectDecimalSeparator(tt.rows); got != tt.want {
+				t.Errorf("DetectDecimalSeparator() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"testdata/statement_sgml.ofx", TypeOFX},
+		{"testdata/statement_xml.ofx", TypeOFX},
+		{"testdata/semicolon_ptbr.csv", TypeCSV},
+		{"testdata/tab.tsv", TypeCSV},
+	}
+	for _, tt := range tests {
+		if got, err := Sniff(tt.file); err != nil || got != tt.want {
+			t.Errorf("Sniff(%s) = %q, %v, want %q", tt.file, got, err, tt.want)
+		}
+	}
+
+	sniffed := []struct {
+		head []byte
+		want string
+		err  error
+	}{
+		{[]byte("PK\x03\x04rest"), TypeXLSX, nil},
+		{[]byte("\xD0\xCF\x11\xE0rest"), "", ErrLegacyXLS},
+		{[]byte("\x00\x01\x02binary"), "", ErrUnsupportedFormat},
+	}
+	for _, tt := range sniffed {
+		if got, err := sniffBytes(tt.head); got != tt.want || err != tt.err {
+			t.Errorf("sniffBytes(%q) = %q, %v, want %q, %v", tt.head, got, err, tt.want, tt.err)
+		}
+	}
+}
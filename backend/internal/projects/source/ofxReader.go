@@ -0,0 +1,199 @@
+package source
+
+import (
+	"finview/backend/internal/analysis"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const TypeOFX = "ofx"
+
+// The statement transactions are exposed as a single sheet.
+const ofxSheetName = "Transacoes"
+
+// OFX fields copied into the data point metadata.
+var ofxMetadataTags = []string{"FITID", "MEMO", "NAME", "TRNTYPE", "CHECKNUM"}
+
+func init() {
+	Register(TypeOFX, func(path string) (SourceReader, error) {
+		series, err := readOFXFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return &ofxFile{series: series}, nil
+	})
+}
+
+type ofxFile struct {
+	series []analysis.TimeSeriesDataPoint
+}
+
+func (o *ofxFile) Sheets() []string {
+	return []string{ofxSheetName}
+}
+
+func (o *ofxFile) Columns(sheet string, headerLine int) ([]string, error) {
+	return columnsFromRows(o, sheet, headerLine)
+}
+
+// Rows renders the transactions as a table whose first line is the header, so
+// OFX projects can also be configured like any spreadsheet.
+func (o *ofxFile) Rows(sheet string, fn func(line int, row []string) bool) error {
+	if sheet != "" && sheet != ofxSheetName {
+		return ErrSheetNotFound
+	}
+
+	header := append([]string{"DTPOSTED", "TRNAMT"}, ofxMetadataTags...)
+	if !fn(1, header) {
+		return nil
+	}
+
+	for i, point := range o.series {
+		row := []string{point.Date.Format("2006-01-02"), strconv.FormatFloat(point.Value, 'f', -1, 64)}
+		for _, tag := range ofxMetadataTags {
+			row = append(row, point.Metadata[tag])
+		}
+		if !fn(i+2, row) {
+			break
+		}
+	}
+	return nil
+}
+
+func (o *ofxFile) Series() ([]analysis.TimeSeriesDataPoint, error) {
+	series := make([]analysis.TimeSeriesDataPoint, len(o.series))
+	copy(series, o.series)
+	return series, nil
+}
+
+func (o *ofxFile) DecimalSeparator() rune {
+	return '.'
+}
+
+func (o *ofxFile) Close() error {
+	return nil
+}
+
+// readOFXFile parses both SGML (1.x) and XML (2.x) statements. In SGML the
+// leaf elements are not closed, so values are read up to the next tag in both
+// cases and only the STMTTRN aggregates are relied on being closed.
+func readOFXFile(path string) ([]analysis.TimeSeriesDataPoint, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	text, _ := decodeText(raw)
+	// Tags are searched in upper case and the offsets used to slice text.
+	upper := asciiUpper(text)
+	if !strings.Contains(upper, "<OFX>") {
+		return nil, ErrOFXMissingRoot
+	}
+
+	var series []analysis.TimeSeriesDataPoint
+	offset := 0
+	for {
+		start := strings.Index(upper[offset:], "<STMTTRN>")
+		if start == -1 {
+			break
+		}
+		start += offset + len("<STMTTRN>")
+
+		end := strings.Index(upper[start:], "</STMTTRN>")
+		if end == -1 {
+			return nil, ErrOFXUnclosedTrn
+		}
+		end += start
+		offset = end + len("</STMTTRN>")
+
+		fields := parseOFXFields(text[start:end])
+
+		date, err := parseOFXDate(fields["DTPOSTED"])
+		if err != nil {
+			continue
+		}
+		value, err := parseOFXAmount(fields["TRNAMT"])
+		if err != nil {
+			continue
+		}
+
+		metadata := map[string]string{}
+		for _, tag := range ofxMetadataTags {
+			if v := fields[tag]; v != "" {
+				metadata[tag] = v
+			}
+		}
+
+		series = append(series, analysis.TimeSeriesDataPoint{
+			Date:        date,
+			Value:       value,
+			Description: firstNonEmpty(fields["MEMO"], fields["NAME"]),
+			Metadata:    metadata,
+		})
+	}
+
+	return series, nil
+}
+
+// asciiUpper upper-cases ASCII letters only, so the result has the same byte
+// offsets as s; strings.ToUpper may change the length of other characters.
+func asciiUpper(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if 'a' <= c && c <= 'z' {
+			b[i] = c - 'a' + 'A'
+		}
+	}
+	return string(b)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func parseOFXFields(block string) map[string]string {
+	fields := map[string]string{}
+	for _, part := range strings.Split(block, "<")[1:] {
+		closeIdx := strings.Index(part, ">")
+		if closeIdx == -1 || strings.HasPrefix(part, "/") {
+			continue
+		}
+		tag := strings.ToUpper(strings.TrimSpace(part[:closeIdx]))
+		value := strings.TrimSpace(part[closeIdx+1:])
+		if value != "" {
+			fields[tag] = unescapeOFX(value)
+		}
+	}
+	return fields
+}
+
+func unescapeOFX(value string) string {
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&", "&quot;", `"`, "&apos;", "'").Replace(value)
+}
+
+// parseOFXDate reads the YYYYMMDD prefix of DTPOSTED and ignores the time and
+// timezone parts (e.g. "20240115120000[-3:BRT]").
+func parseOFXDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("unable to parse date: %s", value)
+	}
+	return time.Parse("20060102", value[:8])
+}
+
+// parseOFXAmount accepts the spec's dot decimal and the comma some Brazilian
+// banks emit instead.
+func parseOFXAmount(value string) (float64, error) {
+	clean := strings.ReplaceAll(value, " ", "")
+	if !strings.Contains(clean, ".") {
+		clean = strings.ReplaceAll(clean, ",", ".")
+	}
+	return strconv.ParseFloat(clean, 64)
+}
@@ -0,0 +1,104 @@
+package source
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReadOFXFile(t *testing.T) {
+	type transaction struct {
+		date        time.Time
+		value       float64
+		description string
+		fitid       string
+	}
+	tests := []struct {
+		file string
+		want []transaction
+	}{
+		{
+			// SGML leaves are not closed, tags may be lower case and
+			// transactions without a usable date are skipped.
+			file: "testdata/statement_sgml.ofx",
+			want: []transaction{
+				{time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), -1500, "ALUGUEL JANEIRO", "202401050001"},
+				{time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), 3200.5, "CLIENTE ACME", "202401100002"},
+			},
+		},
+		{
+			file: "testdata/statement_xml.ofx",
+			want: []transaction{
+				{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), -89.9, "Tools & parts", "A1"},
+				{time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), 2500, "Invoice 42", "A2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			series, err := readOFXFile(tt.file)
+			if err != nil {
+				t.Fatalf("readOFXFile() error = %v", err)
+			}
+			if len(series) != len(tt.want) {
+				t.Fatalf("got %d transactions, want %d", len(series), len(tt.want))
+			}
+			for i, w := range tt.want {
+				got := series[i]
+				if !got.Date.Equal(w.date) || got.Value != w.value || got.Description != w.description {
+					t.Errorf("transaction %d = %v %v %q, want %v %v %q", i, got.Date, got.Value, got.Description, w.date, w.value, w.description)
+				}
+				if got.Metadata["FITID"] != w.fitid {
+					t.Errorf("transaction %d FITID = %q, want %q", i, got.Metadata["FITID"], w.fitid)
+				}
+			}
+		})
+	}
+}
+
+func TestReadOFXFileMetadata(t *testing.T) {
+	series, err := readOFXFile("testdata/statement_sgml.ofx")
+	if err != nil {
+		t.Fatalf("readOFXFile() error = %v", err)
+	}
+	want := map[string]string{"FITID": "202401050001", "MEMO": "ALUGUEL JANEIRO", "TRNTYPE": "DEBIT", "CHECKNUM": "1234"}
+	for tag, value := range want {
+		if got := series[0].Metadata[tag]; got != value {
+			t.Errorf("metadata %s = %q, want %q", tag, got, value)
+		}
+	}
+}
+
+func TestReadOFXFileInvalid(t *testing.T) {
+	tests := []struct {
+		file string
+		want error
+	}{
+		{"testdata/unclosed.ofx", ErrOFXUnclosedTrn},
+		{"testdata/comma_us.csv", ErrOFXMissingRoot},
+	}
+	for _, tt := range tests {
+		if _, err := readOFXFile(tt.file); !errors.Is(err, tt.want) {
+			t.Errorf("readOFXFile(%s) error = %v, want %v", tt.file, err, tt.want)
+		}
+	}
+}
+
+func TestReadOFXFileMultibyteMemo(t *testing.T) {
+	// Upper-casing "ı" shortens it by a byte, which used to shift the
+	// offsets of every tag after it.
+	series, err := readOFXFile("testdata/multibyte.ofx")
+	if err != nil {
+		t.Fatalf("readOFXFile() error = %v", err)
+	}
+	if len(series) != 2 {
+		t.Fatalf("got %d transactions, want 2", len(series))
+	}
+	if got, want := series[0].Description, "ıııııııı Padaria"; got != want {
+		t.Errorf("first description = %q, want %q", got, want)
+	}
+	if got, want := series[1].Value, 1000.0; got != want {
+		t.Errorf("second amount = %v, want %v", got, want)
+	}
+}
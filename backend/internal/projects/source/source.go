@@ -0,0 +1,137 @@
+package source
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"finview/backend/internal/analysis"
+)
+
+var ErrSheetNotFound = errors.New("sheet not found")
+
+// Errors returned when a file cannot be read as any supported source. The
+// service maps them to catalog codes.
+var (
+	ErrEmptyFile         = errors.New("empty file")
+	ErrLegacyXLS         = errors.New("legacy .xls format is not supported")
+	ErrUnsupportedFormat = errors.New("unsupported file format")
+	ErrOFXMissingRoot    = errors.New("invalid OFX file: <OFX> tag not found")
+	ErrOFXUnclosedTrn    = errors.New("invalid OFX file: unclosed <STMTTRN>")
+)
+
+// SourceReader gives uniform, tabular access to an uploaded project file.
+// Line numbers are 1-based, matching the project's ConfigLine.
+type SourceReader interface {
+	Sheets() []string
+	Columns(sheet string, headerLine int) ([]string, error)
+	Rows(sheet string, fn func(line int, row []string) bool) error
+	Close() error
+}
+
+// SeriesReader is implemented by sources whose records already carry a date
+// and an amount, so they can be analysed without any column configuration.
+type SeriesReader interface {
+	Series() ([]analysis.TimeSeriesDataPoint, error)
+}
+
+// DecimalSeparatorReader is implemented by sources that know which decimal
+// mark their numeric cells use.
+type DecimalSeparatorReader interface {
+	DecimalSeparator() rune
+}
+
+type Opener func(path string) (SourceReader, error)
+
+var registry = map[string]Opener{}
+
+// Register makes a reader available for a file type. Readers call it from
+// their init functions.
+func Register(fileType string, opener Opener) {
+	registry[fileType] = opener
+}
+
+// Open sniffs the file content and opens it with the registered reader.
+func Open(path string) (SourceReader, string, error) {
+	fileType, err := Sniff(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	opener, ok := registry[fileType]
+	if !ok {
+		return nil, "", fmt.Errorf("no reader registered for %s files", fileType)
+	}
+
+	reader, err := opener(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return reader, fileType, nil
+}
+
+// columnsFromRows returns the cells of the header line using the reader's own
+// row iteration.
+func columnsFromRows(r SourceReader, sheet string, headerLine int) ([]string, error) {
+	var columns []string
+	err := r.Rows(sheet, func(line int, row []string) bool {
+		if line == headerLine {
+			columns = row
+			return false
+		}
+		return true
+	})
+	return columns, err
+}
+
+// DecimalSeparator returns the reader's decimal mark, or the one detected in
+// the given rows when the reader does not know it.
+func DecimalSeparator(reader SourceReader, rows [][]string) rune {
+	if r, ok := reader.(DecimalSeparatorReader); ok {
+		return r.DecimalSeparator()
+	}
+	return DetectDecimalSeparator(rows)
+}
+
+// Sniff identifies the file type from its first bytes instead of trusting the
+// extension the user uploaded it with.
+func Sniff(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, 1024)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	head = head[:n]
+
+	if len(head) == 0 {
+		return "", ErrEmptyFile
+	}
+	return sniffBytes(head)
+}
+
+func sniffBytes(head []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		return TypeXLSX, nil
+	case bytes.HasPrefix(head, []byte("\xD0\xCF\x11\xE0")):
+		return "", ErrLegacyXLS
+	}
+
+	upper := bytes.ToUpper(head)
+	if bytes.Contains(upper, []byte("OFXHEADER")) || bytes.Contains(upper, []byte("<OFX>")) {
+		return TypeOFX, nil
+	}
+
+	if bytes.IndexByte(head, 0) == -1 {
+		return TypeCSV, nil
+	}
+	return "", ErrUnsupportedFormat
+}
@@ -0,0 +1,169 @@
+package source
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const TypeXLSX = "xlsx"
+
+func init() {
+	Register(TypeXLSX, openXLSX)
+}
+
+// Built-in number formats that render a date. Time-only formats are left out
+// since they do not name a day.
+var builtInDateFormats = map[int]bool{
+	14: true, 15: true, 16: true, 17: true, 22: true,
+	27: true, 28: true, 29: true, 30: true, 31: true, 32: true, 33: true, 34: true, 35: true, 36: true,
+	50: true, 51: true, 52: true, 53: true, 54: true, 55: true, 56: true, 57: true, 58: true,
+}
+
+type xlsxReader struct {
+	file       *excelize.File
+	date1904   bool
+	dateStyles map[int]bool
+}
+
+func openXLSX(path string) (SourceReader, error) {
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &xlsxReader{file: f, dateStyles: map[int]bool{}}
+	if props, err := f.GetWorkbookProps(); err == nil && props.Date1904 != nil {
+		reader.date1904 = *props.Date1904
+	}
+	return reader, nil
+}
+
+func (r *xlsxReader) Sheets() []string {
+	return r.file.GetSheetList()
+}
+
+func (r *xlsxReader) Columns(sheet string, headerLine int) ([]string, error) {
+	return columnsFromRows(r, sheet, headerLine)
+}
+
+// Rows returns the cells as Excel displays them, except for date cells: their
+// display depends on the format (a plain "14" format shows "01-02-06"), so
+// they are converted from the stored serial number to an ISO date instead.
+func (r *xlsxReader) Rows(sheet string, fn func(line int, row []string) bool) error {
+	rows, err := r.file.Rows(sheet)
+	if err != nil {
+		var notExist excelize.ErrSheetNotExist
+		if errors.As(err, &notExist) {
+			return ErrSheetNotFound
+		}
+		return err
+	}
+	defer rows.Close()
+
+	line := 0
+	for rows.Next() {
+		line++
+		row, err := rows.Columns()
+		if err != nil {
+			return err
+		}
+		for j, cell := range row {
+			if date, ok := r.dateCell(sheet, j+1, line, cell); ok {
+				row[j] = date
+			}
+		}
+		if !fn(line, row) {
+			break
+		}
+	}
+	return rows.Error()
+}
+
+// dateCell returns the ISO date for a numeric cell styled as a date.
+func (r *xlsxReader) dateCell(sheet string, col, line int, formatted string) (string, bool) {
+	if strings.IndexFunc(formatted, unicode.IsDigit) == -1 {
+		return "", false
+	}
+
+	cell, err := excelize.CoordinatesToCellName(col, line)
+	if err != nil {
+		return "", false
+	}
+	styleID, err := r.file.GetCellStyle(sheet, cell)
+	if err != nil || !r.isDateStyle(styleID) {
+		return "", false
+	}
+
+	raw, err := r.file.GetCellValue(sheet, cell, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return "", false
+	}
+	serial, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return "", false
+	}
+	date, err := excelize.ExcelDateToTime(serial, r.date1904)
+	if err != nil {
+		return "", false
+	}
+
+	if date.Hour() == 0 && date.Minute() == 0 && date.Second() == 0 {
+		return date.Format("2006-01-02"), true
+	}
+	return date.Format("2006-01-02 15:04:05"), true
+}
+
+func (r *xlsxReader) isDateStyle(styleID int) bool {
+	if isDate, ok := r.dateStyles[styleID]; ok {
+		return isDate
+	}
+
+	isDate := false
+	if style, err := r.file.GetStyle(styleID); err == nil {
+		if style.CustomNumFmt != nil {
+			isDate = isDateFormatCode(*style.CustomNumFmt)
+		} else {
+			isDate = builtInDateFormats[style.NumFmt]
+		}
+	}
+	r.dateStyles[styleID] = isDate
+	return isDate
+}
+
+// isDateFormatCode reports whether a custom number format shows a day or a
+// year. Quoted text, escaped characters and bracketed sections such as
+// colours or locale tags are ignored; a bare "m" is ambiguous with minutes,
+// so it does not count on its own.
+func isDateFormatCode(code string) bool {
+	inQuotes, inBrackets, escaped := false, false, false
+	for _, r := range strings.ToLower(code) {
+		switch {
+		case escaped:
+			escaped = false
+		case inQuotes:
+			inQuotes = r != '"'
+		case inBrackets:
+			inBrackets = r != ']'
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			inQuotes = true
+		case r == '[':
+			inBrackets = true
+		case r == ';':
+			// Only the first section applies to positive numbers.
+			return false
+		case r == 'd' || r == 'y':
+			return true
+		}
+	}
+	return false
+}
+
+func (r *xlsxReader) Close() error {
+	return r.file.Close()
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"finview/backend/initializers"
+	"finview/backend/internal/i18n"
 	"finview/backend/internal/user/model"
 	"net/http"
 	"os"
@@ -17,9 +18,7 @@ func Login(c *gin.Context){
 		Password string
 	}
 	if c.Bind(&body) !=nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Failed to read body",
-		})
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidBody, i18n.Params{"detail": "body"})
 		return
 	}
 
@@ -27,18 +26,14 @@ func Login(c *gin.Context){
 	initializers.DB.First(&user, "email = ?", body.Email)
 
 	if user.ID == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid email or password",
-		})
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidCredentials, nil)
 		return
 	}
 
 	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.Password))
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Faield to compare password hash",
-		})
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidCredentials, nil)
 		return
 	}
 
@@ -52,9 +47,7 @@ func Login(c *gin.Context){
 
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Failed to create token",
-		})
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeTokenCreationFailed, nil)
 		return
 	}
 
@@ -73,6 +66,7 @@ func Logout(c *gin.Context) {
     c.SetCookie("Authorization", "", -1, "/", "", false, true)
 
     c.JSON(http.StatusOK, gin.H{
-        "message": "Deslogado com sucesso",
+        "code":    i18n.CodeLoggedOut,
+        "message": i18n.Message(c, i18n.CodeLoggedOut),
     })
 }
\ No newline at end of file
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"finview/backend/initializers"
+	"finview/backend/internal/i18n"
 	"finview/backend/internal/user/model"
 	"net/http"
 
@@ -15,18 +16,14 @@ func Signup(c *gin.Context){
 		Password string
 	}
 	if c.Bind(&body) !=nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Failed to read body",
-		})
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeInvalidBody, i18n.Params{"detail": "body"})
 		return
 	}
 
 	hash, err := bcrypt.GenerateFromPassword([]byte(body.Password), 10)
 
 	if err !=nil{
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Failed to hash password",
-		})
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodePasswordHashFailed, nil)
 		return
 	}
 
@@ -34,9 +31,7 @@ func Signup(c *gin.Context){
 	result := initializers.DB.Create(&user)
 
 	if result.Error !=nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Failed to create user",
-		})
+		i18n.RespondCode(c, http.StatusBadRequest, i18n.CodeUserCreationFailed, nil)
 		return
 	}
 
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"finview/backend/initializers"
+	"finview/backend/internal/i18n"
 	"finview/backend/internal/user/model"
 	"net/http"
 	"os"
@@ -16,7 +17,8 @@ func RequireAuth(c *gin.Context){
 	tokenString, err := c.Cookie("Authorization")
 
 	if err != nil{
-		c.AbortWithStatus(http.StatusUnauthorized)
+		abortUnauthorized(c)
+		return
 	}
 
 	
@@ -25,28 +27,37 @@ func RequireAuth(c *gin.Context){
 		return []byte(os.Getenv("SECRET")), nil
 	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 	if err != nil {
-		c.AbortWithStatus(http.StatusUnauthorized)
+		abortUnauthorized(c)
 		return
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok {
 
 		if float64(time.Now().Unix()) > claims["exp"].(float64){
-			c.AbortWithStatus(http.StatusUnauthorized)
+			abortUnauthorized(c)
+			return
 		}
 
 		var user model.User
 		initializers.DB.First(&user, claims["sub"])
 
 		if user.ID == 0 {
-			c.AbortWithStatus(http.StatusUnauthorized)
+			abortUnauthorized(c)
+			return
 		}
 
 		c.Set("user", user)
 
 		c.Next()
 	} else {
-		c.AbortWithStatus(http.StatusUnauthorized)
+		abortUnauthorized(c)
 	}
 
 }
+
+// abortUnauthorized stops the request with the unauthorized error code.
+func abortUnauthorized(c *gin.Context) {
+	i18n.RespondCode(c, http.StatusUnauthorized, i18n.CodeUnauthorized, nil)
+	c.Abort()
+}
+
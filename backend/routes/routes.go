@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"finview/backend/internal/i18n"
 	"finview/backend/internal/user/middleware"
     projectController "finview/backend/internal/projects/controller"
 	userController "finview/backend/internal/user/controller"    
@@ -9,6 +10,8 @@ import (
 )
 
 func SetupRoutes(r *gin.Engine) {
+	r.Use(i18n.Middleware)
+
 	// --- User Routes  ---
 	r.POST("/signup", userController.Signup)
 	r.POST("/login", userController.Login)
@@ -16,6 +19,12 @@ func SetupRoutes(r *gin.Engine) {
 	r.POST("/logout", userController.Logout)
 
 
+	// --- Health Policy Routes ---
+	r.GET("/health-policy", middleware.RequireAuth, projectController.GetUserHealthPolicy)
+	r.PUT("/health-policy", middleware.RequireAuth, projectController.UpdateUserHealthPolicy)
+	r.DELETE("/health-policy", middleware.RequireAuth, projectController.DeleteUserHealthPolicy)
+
+
 	// --- Project Routes ---
 	
 	
@@ -24,9 +33,33 @@ func SetupRoutes(r *gin.Engine) {
 		projectRoutes.POST("/", projectController.UploadProject)
 		projectRoutes.GET("/", projectController.GetUserProjects)
 		projectRoutes.GET("/:id/analysis", projectController.GetProjectAnalysis)
+		projectRoutes.GET("/:id/forecast", projectController.GetProjectForecast)
+		projectRoutes.GET("/:id/schema", projectController.GetProjectSchema)
+		projectRoutes.GET("/:id/preview", projectController.GetProjectPreview)
 		projectRoutes.PUT("/:id/settings", projectController.UpdateProjectSettings)
 		projectRoutes.PUT("/:id/file", projectController.UpdateProjectFile)
 		projectRoutes.DELETE("/:id", projectController.DeleteProject)
 
+		projectRoutes.GET("/:id/transactions", projectController.ListTransactions)
+		projectRoutes.POST("/:id/transactions", projectController.CreateTransaction)
+		projectRoutes.PUT("/:id/transactions/:transactionId", projectController.UpdateTransaction)
+		projectRoutes.DELETE("/:id/transactions/:transactionId", projectController.DeleteTransaction)
+
+		projectRoutes.GET("/:id/health-policy", projectController.GetProjectHealthPolicy)
+		projectRoutes.PUT("/:id/health-policy", projectController.UpdateProjectHealthPolicy)
+		projectRoutes.DELETE("/:id/health-policy", projectController.DeleteProjectHealthPolicy)
+
+		projectRoutes.GET("/:id/scenarios", projectController.ListScenarios)
+		projectRoutes.POST("/:id/scenarios", projectController.CreateScenario)
+		projectRoutes.GET("/:id/scenarios/:scenarioId", projectController.GetScenario)
+		projectRoutes.PUT("/:id/scenarios/:scenarioId", projectController.UpdateScenario)
+		projectRoutes.DELETE("/:id/scenarios/:scenarioId", projectController.DeleteScenario)
+		projectRoutes.GET("/:id/scenarios/:scenarioId/comparison", projectController.CompareScenario)
+
+		projectRoutes.GET("/:id/budget", projectController.ListBudget)
+		projectRoutes.PUT("/:id/budget", projectController.SaveBudgetLines)
+		projectRoutes.POST("/:id/budget/import", projectController.ImportBudget)
+		projectRoutes.DELETE("/:id/budget/:lineId", projectController.DeleteBudgetLine)
+
 	}
 }
\ No newline at end of file
@@ -71,6 +71,14 @@ const ProjectAnalysisPage = () => {
 
   if (!stats) return null;
 
+  const runway = stats.health?.runway_months;
+  // A null runway means the cash never runs out only for a profitable project;
+  // otherwise there is not enough data to estimate it.
+  const neverRunsOut = stats.health?.status_code === 'profitable';
+  const runwayLabel = runway != null
+    ? runway > 100 ? "+100 Meses" : `${runway.toFixed(1)} Meses`
+    : neverRunsOut ? "Sem previsão de fim" : "Indisponível";
+
   return (
     <div className="layout-container">
       <Sidebar />
@@ -108,10 +116,10 @@ const ProjectAnalysisPage = () => {
 
           <StatCard 
              title="Runway Estimado" 
-             value={stats.health?.runway_months > 100 ? "+100 Meses" : `${stats.health?.runway_months.toFixed(1)} Meses`}
+             value={runwayLabel}
              sub={`Data prevista: ${stats.health?.predicted_date || 'Indefinida'}`}
-             isSuccess={stats.health?.runway_months > 6}
-             isDanger={stats.health?.runway_months < 3}
+             isSuccess={runway == null ? neverRunsOut : runway > 6}
+             isDanger={runway != null && runway < 3}
           />
         </section>
 
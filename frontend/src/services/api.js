@@ -51,8 +51,24 @@ export const projectService = {
   updateSettings: (id, settings) => api.put(`/projects/${id}/settings`, settings),
   getAll: () => api.get('/projects/'),
 
-  getAnalysis: (projectId, type = 'full_analysis') => 
-    api.get(`/projects/${projectId}/analysis?type=${type}`),
+  getAnalysis: (projectId, type = 'full_analysis', params = {}) =>
+    api.get(`/projects/${projectId}/analysis`, { params: { type, ...params } }),
+
+  getForecast: (projectId, params = {}) =>
+    api.get(`/projects/${projectId}/forecast`, { params }),
+
+  getScenarios: (projectId) => api.get(`/projects/${projectId}/scenarios`),
+  createScenario: (projectId, scenario) => api.post(`/projects/${projectId}/scenarios`, scenario),
+  updateScenario: (projectId, scenarioId, scenario) =>
+    api.put(`/projects/${projectId}/scenarios/${scenarioId}`, scenario),
+  deleteScenario: (projectId, scenarioId) => api.delete(`/projects/${projectId}/scenarios/${scenarioId}`),
+  compareScenario: (projectId, scenarioId, params = {}) =>
+    api.get(`/projects/${projectId}/scenarios/${scenarioId}/comparison`, { params }),
+
+  getBudget: (projectId) => api.get(`/projects/${projectId}/budget`),
+  saveBudget: (projectId, lines) => api.put(`/projects/${projectId}/budget`, { lines }),
+  importBudget: (projectId, settings) => api.post(`/projects/${projectId}/budget/import`, settings),
+  deleteBudgetLine: (projectId, lineId) => api.delete(`/projects/${projectId}/budget/${lineId}`),
 
   updateFile: (id, file) => {
     const formData = new FormData();